@@ -1,12 +1,21 @@
 package main
 
 import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"io/ioutil"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/nsf/termbox-go"
 )
@@ -14,8 +23,30 @@ import (
 // Action for S3 object
 type Action struct {
 
-	// object which returns S3 API
-	object *s3.GetObjectOutput
+	// S3 service instance
+	service *s3.S3
+
+	// Bucket name
+	bucket string
+
+	// Full object key
+	key string
+
+	// object metadata which returns S3 API, nil for delete marker
+	head *s3.HeadObjectOutput
+
+	// object tags and its fetching error
+	tags    Tags
+	tagsErr error
+
+	// Calculated checksums of object body
+	checksums []string
+
+	// Selected version, nil for current object
+	version *Version
+
+	// Deleted flag, object is hidden by delete marker
+	deleted bool
 
 	// Status Writer
 	status *Status
@@ -31,13 +62,21 @@ type Action struct {
 
 	// Duplicate guard
 	guard chan struct{}
+
+	// Stop channel of polling archive restore status
+	stop chan struct{}
+
+	// Action menu is being chosen, polled metadata redraws info panel
+	menu bool
 }
 
 // Create Action pointer
-func NewAction(object *s3.GetObjectOutput, objectName string, selector *Selector, status *Status, offset int) *Action {
+func NewAction(service *s3.S3, bucket, key string, selector *Selector, status *Status, offset int) *Action {
 	return &Action{
-		object:   object,
-		name:     objectName,
+		service:  service,
+		bucket:   bucket,
+		key:      key,
+		name:     path.Base(key),
 		offset:   offset,
 		selector: selector,
 		status:   status,
@@ -45,8 +84,49 @@ func NewAction(object *s3.GetObjectOutput, objectName string, selector *Selector
 	}
 }
 
-// Do action
-func (a *Action) Do() (bool, error) {
+// Set specific version for action
+func (a *Action) WithVersion(version *Version) *Action {
+	a.version = version
+	return a
+}
+
+// Mark object as hidden by delete marker
+func (a *Action) Deleted() *Action {
+	a.deleted = true
+	return a
+}
+
+// Start action, object metadata is fetched and archive restore status is polled until stopped
+func (a *Action) Start() error {
+	if err := a.fetchHead(); err != nil {
+		return err
+	}
+	a.startPolling()
+	return nil
+}
+
+// Start polling archive restore status, previous polling is stopped
+func (a *Action) startPolling() {
+	a.Stop()
+	a.stop = make(chan struct{})
+	go a.pollRestore(a.head, a.stop)
+}
+
+// Check action is started
+func (a *Action) started() bool {
+	return a.stop != nil
+}
+
+// Stop polling archive restore status
+func (a *Action) Stop() {
+	if a.stop != nil {
+		close(a.stop)
+		a.stop = nil
+	}
+}
+
+// Choose action and do it, then returns navigation to the next screen
+func (a *Action) Step() (Navigation, error) {
 	a.guard <- struct{}{}
 	defer func() {
 		<-a.guard
@@ -55,14 +135,40 @@ func (a *Action) Do() (bool, error) {
 	pointer := a.displayObjectInfo()
 	a.status.Message("Choose Action for this file", 0)
 
+	var err error
 	switch a.chooseAction(pointer) {
+	case View:
+		return a.doView()
+	case Edit:
+		err = a.doEdit()
+	case Open:
+		err = a.doOpen()
 	case Download:
-		return a.doDownload()
-	case Back, None:
-		return false, nil
+		return navPop(), a.doDownload()
+	case Hash:
+		err = a.doHash()
+	case EditMetadata:
+		return navPop(), a.doEditMetadata()
+	case EditTags:
+		err = a.doEditTags()
+	case Transition:
+		return navPop(), a.doTransition()
+	case Share:
+		err = a.doShare()
+	case RestoreArchive:
+		err = a.doRestoreArchive()
+	case History:
+		return a.doVersions()
+	case Restore:
+		return navPop(), a.doRestore()
+	case Undelete:
+		return navPop(), a.doUndelete()
+	case DeleteVersion:
+		return navPop(), a.doDeleteVersion(pointer)
 	default:
-		return false, nil
+		return navPop(), nil
 	}
+	return navStay(), err
 }
 
 func (a *Action) resize() {
@@ -74,14 +180,38 @@ func (a *Action) resize() {
 // Display object info
 func (a *Action) displayObjectInfo() (pointer int) {
 	pointer = a.offset
-	infoList := [6]string{
+	infoList := []string{
 		"",
 		fmt.Sprint(strings.Repeat("=", 60)),
-		fmt.Sprintf("%-16s: %s\n", "Content Type", *a.object.ContentType),
-		fmt.Sprintf("%-16s: %d (bytes)\n", "File Size", *a.object.ContentLength),
-		fmt.Sprintf("%-16s: %s\n", "Last Modified", utcToJst(*a.object.LastModified)),
-		"",
 	}
+	if a.head != nil {
+		infoList = append(infoList, headInfo(a.head)...)
+		infoList = append(infoList, tagsInfo(a.tags, a.tagsErr)...)
+	}
+	infoList = append(infoList, a.checksums...)
+	if a.deleted {
+		infoList = append(infoList,
+			fmt.Sprintf("%-24s: %s", "Status", "Deleted (hidden by delete marker)"),
+		)
+	}
+	if a.version != nil {
+		latest := "No"
+		if a.version.latest {
+			latest = "Yes"
+		}
+		infoList = append(infoList,
+			fmt.Sprintf("%-24s: %s", "Latest", latest),
+		)
+		if a.version.deleteMarker {
+			infoList = append(infoList,
+				fmt.Sprintf("%-24s: %s", "Version ID", a.version.versionId),
+				fmt.Sprintf("%-24s: %s", "Last Modified", localTime(a.version.lastModified)),
+				fmt.Sprintf("%-24s: %s", "Delete Marker", "Yes"),
+			)
+		}
+	}
+	infoList = append(infoList, "")
+
 	for _, info := range infoList {
 		for i, r := range []rune(info) {
 			termbox.SetCell(i, pointer, r, termbox.ColorDefault, termbox.ColorDefault)
@@ -91,45 +221,621 @@ func (a *Action) displayObjectInfo() (pointer int) {
 	return
 }
 
+// Format object metadata and headers for info panel
+func headInfo(head *s3.HeadObjectOutput) []string {
+	value := func(v *string) string {
+		if v == nil || *v == "" {
+			return "-"
+		}
+		return *v
+	}
+	date := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return localTime(*t)
+	}
+	storageClass := "STANDARD"
+	if head.StorageClass != nil {
+		storageClass = *head.StorageClass
+	}
+	if head.ArchiveStatus != nil {
+		storageClass += " (" + *head.ArchiveStatus + ")"
+	}
+	_, restore := restoreStatus(head.Restore)
+
+	infoList := []string{
+		fmt.Sprintf("%-24s: %s", "Content Type", value(head.ContentType)),
+		fmt.Sprintf("%-24s: %d (bytes)", "File Size", aws.Int64Value(head.ContentLength)),
+		fmt.Sprintf("%-24s: %s", "Last Modified", date(head.LastModified)),
+		fmt.Sprintf("%-24s: %s", "ETag", value(head.ETag)),
+		fmt.Sprintf("%-24s: %s", "Storage Class", storageClass),
+		fmt.Sprintf("%-24s: %s", "Version ID", value(head.VersionId)),
+		fmt.Sprintf("%-24s: %s", "Server Side Encryption", value(head.ServerSideEncryption)),
+		fmt.Sprintf("%-24s: %s", "KMS Key ID", value(head.SSEKMSKeyId)),
+		fmt.Sprintf("%-24s: %s", "Cache Control", value(head.CacheControl)),
+		fmt.Sprintf("%-24s: %s", "Content Encoding", value(head.ContentEncoding)),
+		fmt.Sprintf("%-24s: %s", "Content Disposition", value(head.ContentDisposition)),
+		fmt.Sprintf("%-24s: %s", "Expiration", value(head.Expiration)),
+		fmt.Sprintf("%-24s: %s", "Restore", restore),
+		fmt.Sprintf("%-24s: %s", "Object Lock Mode", value(head.ObjectLockMode)),
+		fmt.Sprintf("%-24s: %s", "Object Lock Retain Until", date(head.ObjectLockRetainUntilDate)),
+		fmt.Sprintf("%-24s: %s", "Object Lock Legal Hold", value(head.ObjectLockLegalHoldStatus)),
+		fmt.Sprintf("%-24s: %s", "Replication Status", value(head.ReplicationStatus)),
+	}
+
+	keys := []string{}
+	for k := range head.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		infoList = append(infoList, fmt.Sprintf("%-24s: %s", "x-amz-meta-"+strings.ToLower(k), value(head.Metadata[k])))
+	}
+	return infoList
+}
+
 // Choose action for selected object
 func (a *Action) chooseAction(pointer int) ObjectAction {
-	back := ActionCommand{op: Back, name: "Back To List"}
-	download := ActionCommand{op: Download, name: "Download this file"}
-
-	actions := ActionList{back, download}
+	actions := ActionList{
+		ActionCommand{op: Back, name: "Back To List"},
+	}
+	switch {
+	case a.deleted:
+		actions = append(actions,
+			ActionCommand{op: Undelete, name: "Undelete this file"},
+			ActionCommand{op: History, name: "Show version history"},
+		)
+	case a.version == nil:
+		actions = append(actions,
+			ActionCommand{op: View, name: "View this file"},
+			ActionCommand{op: Edit, name: "Edit this file in $EDITOR"},
+			ActionCommand{op: Open, name: "Open with..."},
+			ActionCommand{op: Download, name: "Download this file"},
+			ActionCommand{op: Share, name: "Share link (presigned URL)"},
+			ActionCommand{op: Hash, name: "Calculate checksums"},
+			ActionCommand{op: EditMetadata, name: "Edit metadata"},
+			ActionCommand{op: EditTags, name: "Edit tags"},
+			ActionCommand{op: Transition, name: "Change storage class"},
+		)
+		if a.isArchive() {
+			actions = append(actions, ActionCommand{op: RestoreArchive, name: "Restore from archive"})
+		}
+		actions = append(actions,
+			ActionCommand{op: History, name: "Show version history"},
+		)
+	case a.version.deleteMarker:
+		actions = append(actions,
+			ActionCommand{op: DeleteVersion, name: "Delete this delete marker permanently"},
+		)
+	default:
+		actions = append(actions,
+			ActionCommand{op: View, name: "View this version"},
+			ActionCommand{op: Open, name: "Open with..."},
+			ActionCommand{op: Download, name: "Download this version"},
+			ActionCommand{op: Share, name: "Share link (presigned URL)"},
+			ActionCommand{op: Hash, name: "Calculate checksums"},
+		)
+		if a.isArchive() {
+			actions = append(actions, ActionCommand{op: RestoreArchive, name: "Restore from archive"})
+		}
+		if !a.version.latest {
+			actions = append(actions, ActionCommand{op: Restore, name: "Restore this version (copy to latest)"})
+		}
+		actions = append(actions, ActionCommand{op: DeleteVersion, name: "Delete this version permanently"})
+	}
 
 	a.selector.SetOffset(pointer).WithOutFilter()
 	defer func() {
 		a.selector.SetOffset(a.offset).WithFilter()
 	}()
 
-	action, _ := a.selector.Choose(actions.Selectable())
-	switch action {
-	case 0:
-		return Back
-	case 2:
-		return Download
-	default:
+	a.menu = true
+	index, err := a.selector.Choose(actions.Selectable())
+	a.menu = false
+	if err != nil || index < 0 || index >= len(actions) {
 		return None
 	}
+	return actions[index].op
+}
+
+// Confirm destructive operation
+func (a *Action) confirm(message string, pointer int) bool {
+	a.selector.SetOffset(pointer)
+	defer a.selector.SetOffset(a.offset)
+
+	return a.selector.Confirm(message)
+}
+
+// Fetch object metadata. Delete marker doesn't have any metadata
+func (a *Action) fetchHead() error {
+	if a.deleted || (a.version != nil && a.version.deleteMarker) {
+		return nil
+	}
+	a.status.Message("Retriving object metadata...", 0)
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(a.key),
+	}
+	if a.version != nil {
+		input = input.SetVersionId(a.version.versionId)
+	}
+	head, err := a.service.HeadObject(input)
+	if err != nil {
+		return err
+	}
+	a.head = head
+
+	// Tags are optional, so display error instead of returning
+	versionId := ""
+	if a.version != nil {
+		versionId = a.version.versionId
+	}
+	a.tags, a.tagsErr = fetchTags(a.service, a.bucket, a.key, versionId)
+	return nil
+}
+
+// Check object is stored in archive
+func (a *Action) isArchive() bool {
+	return a.head != nil && (isArchived(aws.StringValue(a.head.StorageClass)) || a.head.ArchiveStatus != nil)
+}
+
+// Poll object metadata while restoring is ongoing. Polled metadata is applied on choosing goroutine
+// of selector, and info panel is redrawn while action menu is being chosen
+func (a *Action) pollRestore(head *s3.HeadObjectOutput, stop chan struct{}) {
+	for head != nil {
+		if ongoing, _ := restoreStatus(head.Restore); !ongoing {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(30 * time.Second):
+		}
+		input := &s3.HeadObjectInput{
+			Bucket: aws.String(a.bucket),
+			Key:    aws.String(a.key),
+		}
+		if a.version != nil {
+			input = input.SetVersionId(a.version.versionId)
+		}
+		polled, err := a.service.HeadObject(input)
+		if err != nil {
+			logger.log("Failed to poll restore status: " + err.Error())
+			return
+		}
+		applied := a.selector.Update(func() {
+			a.head = polled
+			if a.menu {
+				a.displayObjectInfo()
+			}
+		}, stop)
+		if !applied {
+			return
+		}
+		head = polled
+	}
+}
+
+// Open object body. Caller must close it after use
+func (a *Action) open() (io.ReadCloser, error) {
+	if !isReadable(a.head) {
+		return nil, &archivedError{storageClass: aws.StringValue(a.head.StorageClass)}
+	}
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(a.key),
+	}
+	if a.version != nil {
+		input = input.SetVersionId(a.version.versionId)
+	}
+	// Ensure body is the same object as displayed metadata
+	if a.head != nil && a.head.ETag != nil {
+		input = input.SetIfMatch(*a.head.ETag)
+	}
+	object, err := a.service.GetObject(input)
+	if err != nil {
+		return nil, err
+	}
+	return object.Body, nil
+}
+
+// Read object and show it in viewer screen
+func (a *Action) doView() (Navigation, error) {
+	if size := aws.Int64Value(a.head.ContentLength); size > maxViewSize {
+		<-a.status.Error(fmt.Sprintf("%s is too large to view", a.name), 1)
+		return navStay(), nil
+	}
+	a.status.Info(fmt.Sprintf("Reading %s ...", a.name), 0)
+	body, err := a.open()
+	if aerr, ok := err.(*archivedError); ok {
+		<-a.status.Error(aerr.Error(), 2)
+		return navStay(), nil
+	} else if err != nil {
+		return navStay(), err
+	}
+	defer body.Close()
+
+	buffer, err := ioutil.ReadAll(io.LimitReader(body, maxViewSize+1))
+	if err != nil {
+		return navStay(), err
+	}
+	return navPush(&Screen{kind: ViewerScreen, name: a.name, content: buffer}), nil
+}
+
+// Edit object in $EDITOR and upload it if the object is not changed by others
+func (a *Action) doEdit() error {
+	if size := aws.Int64Value(a.head.ContentLength); size > maxEditSize {
+		<-a.status.Error(fmt.Sprintf("%s is too large to edit", a.name), 1)
+		return nil
+	}
+	if a.tagsErr != nil {
+		<-a.status.Failure("Cannot keep tags", a.tagsErr, 1)
+		return nil
+	}
+	a.status.Info(fmt.Sprintf("Reading %s ...", a.name), 0)
+	body, err := a.open()
+	if aerr, ok := err.(*archivedError); ok {
+		<-a.status.Error(aerr.Error(), 2)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	before, err := ioutil.ReadAll(io.LimitReader(body, maxEditSize+1))
+	if err != nil {
+		return err
+	}
+	if isBinary(before) {
+		<-a.status.Error(fmt.Sprintf("%s seems to be a binary file", a.name), 1)
+		return nil
+	}
+	after, err := editContent(a.selector, a.name, before)
+	if err != nil {
+		<-a.status.Error(err.Error(), 2)
+		return nil
+	}
+	if string(after) == string(before) {
+		<-a.status.Warn("Nothing to change", 1)
+		return nil
+	}
+
+	// Uploading resets ACL, so grants are kept if readable
+	preview := diffLines(string(before), string(after))
+	grants, err := fetchGrants(a.service, a.bucket, a.key, "")
+	if err != nil {
+		logger.log(fmt.Sprintf("Failed to get ACL of %s: %s", a.key, err))
+		preview = append([]string{fmt.Sprintf("! ACL is not readable (%s), it will be reset to private", errorCode(err)), ""}, preview...)
+	}
+
+	a.selector.SetOffset(a.offset)
+	if !a.selector.Preview(preview, "Upload changes? (Enter: upload, Esc: discard)") {
+		return nil
+	}
+
+	a.status.Info(fmt.Sprintf("Uploading %s ...", a.name), 0)
+	input := metadataFromHead(a.head).putInput(a.bucket, a.key, a.head, grants, after)
+	if len(a.tags) > 0 {
+		input.Tagging = aws.String(a.tags.query())
+	}
+	req, _ := a.service.PutObjectRequest(input)
+	// PutObjectInput doesn't have IfMatch field, so set conditional write header manually
+	req.HTTPRequest.Header.Set("If-Match", aws.StringValue(a.head.ETag))
+	if err := req.Send(); err != nil {
+		switch errorCode(err) {
+		case "PreconditionFailed", "ConditionalRequestConflict":
+			<-a.status.Error("Object has been changed by others, upload is canceled", 2)
+		default:
+			<-a.status.Failure("Failed to upload", err, 2)
+		}
+		return nil
+	}
+	if err := a.fetchHead(); err != nil {
+		return err
+	}
+	<-a.status.Info(fmt.Sprintf("Uploaded %s", a.name), 1)
+	return nil
 }
 
 // Download object to current working directory
-func (a *Action) doDownload() (bool, error) {
+func (a *Action) doDownload() error {
 	a.status.Info(fmt.Sprintf("Downloading %s ...", a.name), 0)
 
-	buffer, err := ioutil.ReadAll(a.object.Body)
+	err := a.saveTo(filepath.Join(config.downloadDir(), a.name))
+	if aerr, ok := err.(*archivedError); ok {
+		<-a.status.Error(aerr.Error(), 2)
+		return nil
+	} else if err != nil {
+		<-a.status.Failure("Failed to download", err, 2)
+		return nil
+	}
+	go func() {
+		<-a.status.Info("Downloaded completely!", 1)
+	}()
+	return nil
+}
+
+// Save object body to file
+func (a *Action) saveTo(writePath string) error {
+	body, err := a.open()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return writeFile(writePath, body)
+}
+
+// Download object to temporary directory and open it with external program
+func (a *Action) doOpen() error {
+	actions := ActionList{
+		ActionCommand{op: Back, name: "Cancel"},
+	}
+	for _, command := range findOpeners(config.Openers, a.name, aws.StringValue(a.head.ContentType)) {
+		actions = append(actions, ActionCommand{op: OpenWith, name: command})
+	}
+	actions = append(actions, ActionCommand{op: CustomCommand, name: "Enter command..."})
+
+	a.selector.SetOffset(a.offset).WithOutFilter()
+	a.status.Message(fmt.Sprintf("Open %s with (configure [openers] in %s)", a.name, configPath()), 0)
+	index, err := a.selector.Choose(actions.Selectable())
+	a.selector.WithFilter()
+	if err != nil || index < 0 {
+		return nil
+	}
+
+	command := actions[index].name
+	switch actions[index].op {
+	case OpenWith:
+		break
+	case CustomCommand:
+		command, err = a.selector.Prompt("Command ({} is replaced with file path)", "")
+		if err != nil || strings.TrimSpace(command) == "" {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	dir, err := ioutil.TempDir("", "ls3-")
 	if err != nil {
-		return false, err
+		return err
 	}
-	cwd, _ := os.Getwd()
-	writePath := fmt.Sprintf("%s/%s", cwd, a.name)
-	if err := ioutil.WriteFile(writePath, buffer, 0644); err != nil {
-		<-a.status.Error("Failed to download", 1)
-		return false, err
+	defer os.RemoveAll(dir)
+
+	a.status.Info(fmt.Sprintf("Downloading %s ...", a.name), 0)
+	err = a.saveTo(filepath.Join(dir, a.name))
+	if aerr, ok := err.(*archivedError); ok {
+		<-a.status.Error(aerr.Error(), 2)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := openWith(a.selector, command, filepath.Join(dir, a.name)); err != nil {
+		<-a.status.Error(fmt.Sprintf("%s exited with error: %s", command, err), 2)
+	}
+	return nil
+}
+
+// Calculate MD5 and SHA256 checksums of object body
+func (a *Action) doHash() error {
+	a.status.Info(fmt.Sprintf("Calculating checksums of %s ...", a.name), 0)
+
+	body, err := a.open()
+	if aerr, ok := err.(*archivedError); ok {
+		<-a.status.Error(aerr.Error(), 2)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	md5Hash := md5.New()
+	sha256Hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(md5Hash, sha256Hash), body); err != nil {
+		return err
+	}
+	a.checksums = []string{
+		fmt.Sprintf("%-24s: %s", "MD5", hex.EncodeToString(md5Hash.Sum(nil))),
+		fmt.Sprintf("%-24s: %s", "SHA256", hex.EncodeToString(sha256Hash.Sum(nil))),
+	}
+	a.status.Clear()
+	return nil
+}
+
+// Edit metadata of object
+func (a *Action) doEditMetadata() error {
+	a.selector.SetOffset(a.offset)
+	change, ok, err := editMetadata(a.head, a.selector, a.status)
+	if err != nil || !ok {
+		return err
+	}
+	return applyMetadata(a.service, a.bucket, []string{a.key}, change, a.selector, a.status)
+}
+
+// Edit tags of object
+func (a *Action) doEditTags() error {
+	if a.tagsErr != nil {
+		<-a.status.Failure("Cannot read tags", a.tagsErr, 1)
+		return nil
+	}
+	a.selector.SetOffset(a.offset)
+	change, ok, err := editTags(a.tags, a.selector, a.status)
+	if err != nil || !ok {
+		return err
+	}
+	tags, _ := a.tags.Apply(change)
+	a.status.Info("Updating tags...", 0)
+	if err := putTags(a.service, a.bucket, a.key, tags); err != nil {
+		<-a.status.Failure("Failed to update tags", err, 2)
+		return nil
+	}
+	a.tags = tags
+	<-a.status.Info("Updated tags", 1)
+	return nil
+}
+
+// Generate presigned GET URL and copy it to clipboard
+func (a *Action) doShare() error {
+	a.selector.SetOffset(a.offset)
+	expiry, ok, err := chooseExpiry("Share link", a.selector, a.status)
+	if err != nil || !ok {
+		return err
+	}
+	versionId := ""
+	if a.version != nil {
+		versionId = a.version.versionId
+	}
+	url, err := presignGet(a.service, a.bucket, a.key, versionId, expiry)
+	if err != nil {
+		<-a.status.Failure("Failed to presign", err, 2)
+		return nil
+	}
+	showURL(url, expiry, a.selector, a.status)
+	return nil
+}
+
+// Change storage class of object
+func (a *Action) doTransition() error {
+	a.selector.SetOffset(a.offset)
+	current := aws.StringValue(a.head.StorageClass)
+	if current == "" {
+		current = s3.StorageClassStandard
+	}
+	storageClass, ok, err := chooseStorageClass(current, a.selector, a.status)
+	if err != nil || !ok {
+		return err
+	}
+	// Self copy resets ACL, so grants are kept if readable
+	grants, err := fetchGrants(a.service, a.bucket, a.key, "")
+	if err != nil {
+		logger.log(fmt.Sprintf("Failed to get ACL of %s: %s", a.key, err))
+		message := fmt.Sprintf("ACL is not readable (%s) and will be reset to private. Transition to %s?", errorCode(err), storageClass)
+		if !a.selector.Confirm(message) {
+			return nil
+		}
+	}
+	a.status.Info(fmt.Sprintf("Transitioning to %s ...", storageClass), 0)
+	if err := transitionObject(a.service, a.bucket, a.key, a.head, grants, storageClass); err != nil {
+		<-a.status.Failure("Failed to transition", err, 2)
+		return nil
+	}
+	<-a.status.Info(fmt.Sprintf("Transitioned to %s", storageClass), 1)
+	return nil
+}
+
+// Start restoring archived object
+func (a *Action) doRestoreArchive() error {
+	a.selector.SetOffset(a.offset)
+	tier, days, ok, err := editRestore(a.selector, a.status)
+	if err != nil || !ok {
+		return err
+	}
+	versionId := ""
+	if a.version != nil {
+		versionId = a.version.versionId
+	}
+	a.status.Info("Requesting restore...", 0)
+	if err := restoreObject(a.service, a.bucket, a.key, versionId, tier, days); err != nil {
+		<-a.status.Failure("Failed to restore", err, 2)
+		return nil
+	}
+	// Reload metadata to show ongoing restore status, and poll it until restored
+	if err := a.fetchHead(); err != nil {
+		return err
+	}
+	a.startPolling()
+	<-a.status.Info("Restore requested", 1)
+	return nil
+}
+
+// Show version history of object and push action menu for chosen version
+func (a *Action) doVersions() (Navigation, error) {
+	a.status.Message("Retriving version list...", 0)
+	s3Versions, s3Markers, err := fetchVersions(a.service, a.bucket, a.key)
+	if err != nil {
+		return navStay(), err
+	}
+	// Prefix matches other keys like "foo.txt.bak", so filter by exact key
+	versions := Versions{}
+	for _, v := range s3Versions {
+		if *v.Key == a.key {
+			versions = append(versions, NewVersion(a.name, v))
+		}
+	}
+	for _, d := range s3Markers {
+		if *d.Key == a.key {
+			versions = append(versions, NewDeleteMarker(a.name, d))
+		}
+	}
+	sortVersions(versions)
+
+	a.selector.SetOffset(a.offset)
+	a.status.Message("Choose version (Esc: back)", 0)
+	index, err := a.selector.Choose(versions.Selectable())
+	a.status.Clear()
+	if err != nil || index < 0 || index >= len(versions) {
+		return navStay(), nil
+	}
+
+	action := NewAction(a.service, a.bucket, a.key, a.selector, a.status, a.offset).WithVersion(versions[index])
+	return navPush(&Screen{kind: ActionMenuScreen, action: action}), nil
+}
+
+// Restore version by copying it to latest
+func (a *Action) doRestore() error {
+	// Copy resets ACL, so grants of the version are kept if readable
+	grants, err := fetchGrants(a.service, a.bucket, a.key, a.version.versionId)
+	if err != nil {
+		logger.log(fmt.Sprintf("Failed to get ACL of %s: %s", a.key, err))
+		message := fmt.Sprintf("ACL is not readable (%s) and will be reset to private. Restore this version?", errorCode(err))
+		if !a.selector.SetOffset(a.offset).Confirm(message) {
+			return nil
+		}
+	}
+	a.status.Info(fmt.Sprintf("Restoring %s ...", a.version.versionId), 0)
+	input := metadataFromHead(a.head).copyInput(a.bucket, a.key, a.head, grants)
+	input.CopySource = aws.String(copySource(a.bucket, a.key, a.version.versionId))
+	if _, err := a.service.CopyObject(input); err != nil {
+		<-a.status.Failure("Failed to restore", err, 2)
+		return nil
 	}
 	go func() {
-		<-a.status.Info("Downloaded completely!", 1)
+		<-a.status.Info("Restored completely!", 1)
+	}()
+	return nil
+}
+
+// Undelete object by removing delete markers
+func (a *Action) doUndelete() error {
+	a.status.Info(fmt.Sprintf("Undeleting %s ...", a.name), 0)
+	if _, err := undelete(a.service, a.bucket, a.key, true); err != nil {
+		<-a.status.Failure("Failed to undelete", err, 2)
+		return nil
+	}
+	go func() {
+		<-a.status.Info("Undeleted completely!", 1)
+	}()
+	return nil
+}
+
+// Delete version permanently
+func (a *Action) doDeleteVersion(pointer int) error {
+	message := fmt.Sprintf("Delete version %s of %s permanently?", a.version.versionId, a.name)
+	if !a.confirm(message, pointer) {
+		return nil
+	}
+	_, err := a.service.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:    aws.String(a.bucket),
+		Key:       aws.String(a.key),
+		VersionId: aws.String(a.version.versionId),
+	})
+	if err != nil {
+		<-a.status.Failure("Failed to delete", err, 2)
+		return nil
+	}
+	go func() {
+		<-a.status.Info("Deleted completely!", 1)
 	}()
-	return false, nil
+	return nil
 }
@@ -10,6 +10,35 @@ type ObjectAction int
 const (
 	Back ObjectAction = iota
 	Download
+	View
+	Hash
+	History
+	Restore
+	DeleteVersion
+	Undelete
+	Confirm
+	EditMetadata
+	EditTags
+	Transition
+	RestoreArchive
+	Share
+	Properties
+	DeleteBucket
+	Lifecycle
+	EditRule
+	ToggleRule
+	DeleteRule
+	EditPolicy
+	EditCors
+	Edit
+	Open
+	OpenWith
+	CustomCommand
+	Save
+	AddField
+	RemoveField
+	RenameBookmark
+	DeleteBookmark
 	None = 999
 )
 
@@ -33,7 +62,7 @@ func (a ActionCommand) String() string {
 // Writer::Writer implementation
 func (a ActionCommand) Write(y int, filter string) {
 	for i, r := range []rune(a.name) {
-		termbox.SetCell(i, y, r, termbox.ColorWhite, termbox.ColorDefault)
+		termbox.SetCell(i, y, r, theme.Text.Fg, theme.Text.Bg)
 	}
 }
 
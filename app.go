@@ -5,6 +5,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/nsf/termbox-go"
+	"sort"
 	"strings"
 )
 
@@ -20,9 +21,28 @@ type App struct {
 	// Selected object name
 	object string
 
+	// Object name to show action menu, which is given by go to prompt
+	open string
+
+	// Directory or bucket name which we came back from, cursor is moved to it
+	child string
+
+	// Show object versions instead of objects
+	versions bool
+
+	// Show objects which are hidden by delete marker
+	deleted bool
+
+	// Index of objectSortOrders
+	sortOrder int
+
 	// S3 service instance
 	service *s3.S3
 
+	// Profile and region which service is created with
+	profile string
+	region  string
+
 	// Status writer
 	status *Status
 
@@ -31,23 +51,43 @@ type App struct {
 
 	// Action instance
 	action *Action
+
+	// Visited locations
+	history *NavHistory
+
+	// Navigation stack, the last one is current screen
+	screens []*Screen
 }
 
 // Create new application
-func NewApp(service *s3.S3, bucket string) (*App, error) {
+func NewApp(service *s3.S3, bucket string, prefix []string) (*App, error) {
 	if err := termbox.Init(); err != nil {
 		return nil, err
 	}
+	// Alt modifier is used by key bindings, e.g. "alt+v"
+	termbox.SetInputMode(inputMode())
+	termbox.SetOutputMode(theme.outputMode())
 	app := &App{
-		service: service,
+		profile: config.Profile,
+		region:  config.Region,
 		bucket:  bucket,
-		prefix:  []string{},
+		prefix:  prefix,
+		history: NewNavHistory(),
 	}
 	app.status = NewStatus(1)
+	app.service = service
 	app.selector = NewSelector(2, app.status)
 	return app, nil
 }
 
+// Get termbox input mode, mouse is captured unless disabled by configuration
+func inputMode() termbox.InputMode {
+	if config.Mouse {
+		return termbox.InputAlt | termbox.InputMouse
+	}
+	return termbox.InputAlt
+}
+
 // Terminate application
 func (a *App) Terminate() {
 	termbox.Close()
@@ -67,20 +107,16 @@ func (a *App) Run() error {
 	stop := make(chan struct{}, 1)
 	go a.eventLoop(stop)
 
+	screen := &Screen{kind: ObjectListScreen}
 	if a.bucket == "" {
-		if err := a.chooseBuckets(); err != nil {
-			stop <- struct{}{}
-			return err
-		}
-	}
-	if err := a.chooseObject(); err != nil {
-		stop <- struct{}{}
-		return err
+		screen.kind = BucketListScreen
 	}
+	a.screens = []*Screen{screen}
+	err := a.navigate()
 
-	// successfully ended application
+	// successfully ended application unless error is returned
 	stop <- struct{}{}
-	return nil
+	return err
 }
 
 func (a *App) eventLoop(stop chan struct{}) {
@@ -93,6 +129,9 @@ func (a *App) eventLoop(stop chan struct{}) {
 				logger.log("termbox keyEvent handled")
 				// Send key event to selector
 				a.selector.keyPress(evt)
+			case termbox.EventMouse:
+				// Mouse event is handled with key event in selector
+				a.selector.keyPress(evt)
 			case termbox.EventResize:
 				logger.log("termbox resizeEvent handled")
 				a.Clear()
@@ -110,7 +149,15 @@ func (a *App) eventLoop(stop chan struct{}) {
 		case <-stop:
 			return
 		default:
-			queue <- termbox.PollEvent()
+			evt := termbox.PollEvent()
+			if evt.Type != termbox.EventInterrupt {
+				queue <- evt
+				continue
+			}
+			// Suspended by external program, redraw all after resuming
+			a.selector.waitResume()
+			width, height := termbox.Size()
+			queue <- termbox.Event{Type: termbox.EventResize, Width: width, Height: height}
 		}
 	}
 }
@@ -128,39 +175,137 @@ func (a *App) writeHeader() {
 		o = a.object
 	}
 	location := fmt.Sprintf("Location: s3://%s%s%s", b, p, o)
+	if a.versions {
+		location += " [versions]"
+	} else if a.deleted {
+		location += " [deleted]"
+	}
+	if a.sortOrder > 0 {
+		location += " [sort: " + objectSortOrders[a.sortOrder] + "]"
+	}
 	for i, r := range []rune(location) {
-		termbox.SetCell(i, 0, r, termbox.ColorGreen|termbox.AttrBold, termbox.ColorDefault)
+		termbox.SetCell(i, 0, r, theme.Header.Fg, theme.Header.Bg)
+	}
+}
+
+// Find breadcrumb of header at x position. Returns prefix depth, or -1 for bucket list
+func (a *App) breadcrumbAt(x int) (int, bool) {
+	start := len([]rune("Location: "))
+	end := start + len([]rune("s3://"))
+	if x >= start && x < end {
+		return -1, true
+	}
+	if a.bucket == "" {
+		return 0, false
+	}
+	start, end = end, end+len([]rune(a.bucket+"/"))
+	if x >= start && x < end {
+		return 0, true
 	}
+	for i, p := range a.prefix {
+		start, end = end, end+len([]rune(p+"/"))
+		if x >= start && x < end {
+			return i + 1, true
+		}
+	}
+	return 0, false
 }
 
 // Choose bucket from list
-func (a *App) chooseBuckets() error {
+func (a *App) chooseBuckets() (Navigation, error) {
+	a.bucket, a.prefix, a.object = "", []string{}, ""
 	a.status.Message("Retriving bucket list...", 0)
 	result, err := a.service.ListBuckets(&s3.ListBucketsInput{})
 	if err != nil {
-		return err
+		<-a.status.Failure("Failed to list buckets", err, 2)
+		// Bucket list may not be permitted, then stay on the location which we came back from
+		if last, ok := a.history.current(); ok && last.bucket != "" && a.child != "" {
+			a.bucket, a.prefix, a.child = last.bucket, append([]string{}, last.prefix...), ""
+			return navReplace(&Screen{kind: ObjectListScreen}), nil
+		}
+		// Otherwise show empty list to keep goto, bookmarks and recent locations available
+		result = &s3.ListBucketsOutput{}
 	}
 	buckets := Buckets{}
+	names := []string{}
 	for _, b := range result.Buckets {
 		buckets = append(buckets, NewBucket(b))
+		names = append(names, aws.StringValue(b.Name))
 	}
+	a.recordVisit()
+	a.restoreView(names)
 	a.Clear()
 	a.writeHeader()
 
-	a.status.Message("Choose bucket", 0)
+	a.status.Message("Choose bucket ("+keymap.Hint(CmdActions, CmdCreateBucket, CmdGoto, CmdBookmarks, CmdRecent)+")", 0)
+	a.selector.Bind(CmdActions, CmdCreateBucket, CmdGoto, CmdBookmarks, CmdRecent, CmdHistoryBack, CmdHistoryForward)
 	index, err := a.selector.Choose(buckets.Selectable())
-	if err != nil {
-		a.status.Clear()
-	} else {
+	a.selector.Unbind()
+	a.history.saveView(a.currentVisit(), a.selector.LastView())
+	if kerr, ok := err.(*KeyBindError); ok {
+		a.Clear()
+		a.writeHeader()
+		switch {
+		case kerr.Command == CmdCreateBucket:
+			// Reload bucket list to reflect created bucket
+			return navDialog(func() (Navigation, error) {
+				return navStay(), createBucket(a.service, a.selector.SetOffset(2), a.status)
+			}), nil
+		case kerr.Command == CmdActions && index >= 0 && index < len(buckets):
+			return navDialog(func() (Navigation, error) {
+				return navStay(), NewBucketAction(a.service, buckets[index], a.selector, a.status, 2).Do()
+			}), nil
+		case kerr.Command == CmdGoto:
+			return navDialog(func() (Navigation, error) {
+				a.goTo()
+				return a.openLocation(), nil
+			}), nil
+		case kerr.Command == CmdBookmarks:
+			return navDialog(func() (Navigation, error) {
+				a.chooseBookmark()
+				return a.openLocation(), nil
+			}), nil
+		case kerr.Command == CmdRecent:
+			return navDialog(func() (Navigation, error) {
+				a.chooseRecent()
+				return a.openLocation(), nil
+			}), nil
+		case kerr.Command == CmdHistoryBack:
+			a.goHistory(a.history.back())
+		case kerr.Command == CmdHistoryForward:
+			a.goHistory(a.history.forward())
+		}
+		return a.openLocation(), nil
+	} else if err != nil {
 		a.status.Clear()
-		a.bucket = buckets[index].name
+		return quitOnInterrupt(err)
 	}
-	return err
+	a.status.Clear()
+	if index < 0 || index >= len(buckets) {
+		return navStay(), nil
+	}
+	a.bucket = buckets[index].name
+	return a.openLocation(), nil
 }
 
-// Choose from object list
-func (a *App) chooseObject() error {
-	a.object = ""
+// Open object list from bucket list if bucket is chosen
+func (a *App) openLocation() Navigation {
+	if a.bucket == "" {
+		return navStay()
+	}
+	return navPush(&Screen{kind: ObjectListScreen})
+}
+
+// Get current directory prefix
+func (a *App) dir() string {
+	if len(a.prefix) > 0 {
+		return strings.Join(a.prefix, "/") + "/"
+	}
+	return ""
+}
+
+// Fetch object list in current prefix
+func (a *App) listObjects() (Objects, error) {
 	var contents []*s3.Object
 	var lastKey string
 	for {
@@ -169,7 +314,7 @@ func (a *App) chooseObject() error {
 			MaxKeys: aws.Int64(10000),
 		}
 		if len(a.prefix) > 0 {
-			input = input.SetPrefix(strings.Join(a.prefix, "/") + "/")
+			input = input.SetPrefix(a.dir())
 		}
 		if lastKey != "" {
 			logger.log("Extra fetch after key: " + lastKey)
@@ -178,7 +323,7 @@ func (a *App) chooseObject() error {
 		a.status.Message("Retriving object list...", 0)
 		result, err := a.service.ListObjectsV2(input)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		contents = append(contents, result.Contents...)
 		if *result.IsTruncated == true {
@@ -188,72 +333,296 @@ func (a *App) chooseObject() error {
 		}
 		break
 	}
-	objects := Objects{NewParentObject()}
-	for _, o := range formatObjects(contents, a.prefix) {
-		objects = append(objects, o)
+	objects := formatObjects(contents, a.prefix)
+	if !a.deleted {
+		return objects, nil
+	}
+
+	a.status.Message("Retriving deleted object list...", 0)
+	_, s3Markers, err := fetchVersions(a.service, a.bucket, a.dir())
+	if err != nil {
+		return nil, err
+	}
+	return formatDeletedObjects(objects, s3Markers, a.prefix), nil
+}
+
+// Fetch object version list in current prefix
+func (a *App) listVersions() (Objects, Versions, error) {
+	a.status.Message("Retriving object version list...", 0)
+	s3Versions, s3Markers, err := fetchVersions(a.service, a.bucket, a.dir())
+	if err != nil {
+		return nil, nil, err
+	}
+	objects, versions := formatVersions(s3Versions, s3Markers, a.prefix)
+	return objects, versions, nil
+}
+
+// Choose from object list
+func (a *App) chooseObject(screen *Screen) (Navigation, error) {
+	if a.open != "" {
+		a.object, a.open = a.open, ""
+		return a.objectAction(nil), nil
+	}
+	a.object = ""
+	var objects Objects
+	var versions Versions
+	var err error
+	if a.versions {
+		objects, versions, err = a.listVersions()
+	} else {
+		objects, err = a.listObjects()
+	}
+	if err != nil {
+		<-a.status.Failure(fmt.Sprintf("Failed to list s3://%s/%s", a.bucket, a.dir()), err, 2)
+		return a.restoreListed(screen), nil
 	}
+	screen.listed = &Visit{bucket: a.bucket, prefix: append([]string{}, a.prefix...)}
+	screen.versions, screen.deleted = a.versions, a.deleted
+
+	sortObjects(objects, objectSortOrders[a.sortOrder])
+	objects = append(Objects{NewParentObject()}, objects...)
+	list := objects.Selectable()
+	names := []string{}
+	for _, o := range objects {
+		if o.dir {
+			names = append(names, o.key)
+		} else {
+			names = append(names, "")
+		}
+	}
+	for _, v := range versions {
+		list = append(list, v)
+	}
+	a.recordVisit()
+	a.restoreView(names)
 
 	a.Clear()
 	a.writeHeader()
 
-	a.status.Message("Choose object", 0)
-	index, err := a.selector.Choose(objects.Selectable())
-	if err != nil {
+	a.status.Message("Choose object ("+keymap.Hint(CmdMark, CmdActions, CmdSort, CmdVersions, CmdDeleted, CmdUndelete, CmdUploadURL, CmdGoto, CmdBookmark, CmdBookmarks, CmdRecent)+")", 0)
+	a.selector.Bind(CmdSort, CmdVersions, CmdDeleted, CmdUndelete, CmdActions, CmdUploadURL, CmdGoto, CmdBookmark, CmdBookmarks, CmdRecent, CmdHistoryBack, CmdHistoryForward, CmdJump).WithMark()
+	index, err := a.selector.Choose(list)
+	a.selector.Unbind().WithOutMark()
+	a.history.saveView(a.currentVisit(), a.selector.LastView())
+	if kerr, ok := err.(*KeyBindError); ok {
+		switch kerr.Command {
+		case CmdSort:
+			a.sortOrder = (a.sortOrder + 1) % len(objectSortOrders)
+		case CmdVersions:
+			a.versions = !a.versions
+		case CmdDeleted:
+			a.deleted = !a.deleted
+		case CmdUndelete:
+			if index >= 0 && index < len(objects) {
+				return navDialog(func() (Navigation, error) {
+					return navStay(), a.undeleteObject(objects[index])
+				}), nil
+			}
+		case CmdActions:
+			marked := a.selector.Marked()
+			return navDialog(func() (Navigation, error) {
+				return navStay(), a.batchAction(objects, index, marked)
+			}), nil
+		case CmdUploadURL:
+			return navDialog(func() (Navigation, error) {
+				return navStay(), a.uploadURL()
+			}), nil
+		case CmdGoto:
+			return navDialog(func() (Navigation, error) {
+				a.goTo()
+				return navStay(), nil
+			}), nil
+		case CmdBookmark:
+			return navDialog(func() (Navigation, error) {
+				a.addBookmark()
+				return navStay(), nil
+			}), nil
+		case CmdBookmarks:
+			return navDialog(func() (Navigation, error) {
+				a.chooseBookmark()
+				return navStay(), nil
+			}), nil
+		case CmdRecent:
+			return navDialog(func() (Navigation, error) {
+				a.chooseRecent()
+				return navStay(), nil
+			}), nil
+		case CmdHistoryBack:
+			a.goHistory(a.history.back())
+		case CmdHistoryForward:
+			a.goHistory(a.history.forward())
+		case CmdJump:
+			if depth, ok := a.breadcrumbAt(a.selector.ClickedX()); ok {
+				if depth < 0 {
+					a.child = a.bucket
+					a.bucket = ""
+				} else if depth < len(a.prefix) {
+					a.child = a.prefix[depth]
+					a.prefix = a.prefix[0:depth]
+				}
+			}
+		}
+		// History may go back to bucket list
+		if a.bucket == "" {
+			return a.toBucketList(), nil
+		}
+		return navStay(), nil
+	} else if err != nil {
 		a.status.Clear()
-		return err
+		return quitOnInterrupt(err)
 	}
 
 	a.status.Clear()
+	if index >= len(objects) {
+		version := versions[index-len(objects)]
+		a.object = version.key
+		return a.objectAction(version), nil
+	}
+
 	selected := objects[index]
 	switch {
 	case selected.key == "../": // selcted parent directory
 		a.object = ""
 		// if prefix is empty, back to choose bucket
 		if len(a.prefix) == 0 {
-			a.bucket = ""
-			if err := a.chooseBuckets(); err != nil {
-				return err
-			}
-		} else {
-			a.prefix = a.prefix[0 : len(a.prefix)-1]
+			a.child = a.bucket
+			return a.toBucketList(), nil
 		}
+		a.child = a.prefix[len(a.prefix)-1]
+		a.prefix = a.prefix[0 : len(a.prefix)-1]
 	case selected.dir:
 		a.object = ""
 		a.prefix = append(a.prefix, selected.key)
 		logger.log("Directory selected" + selected.key)
+	case selected.deleted:
+		a.object = selected.key
+		action := NewAction(a.service, a.bucket, a.dir()+a.object, a.selector, a.status, 2).Deleted()
+		return navPush(&Screen{kind: ActionMenuScreen, action: action}), nil
 	default:
 		a.object = selected.key
-		if isEnd, err := a.objectAction(); err != nil {
-			return err
-		} else if isEnd {
-			return nil
-		}
+		return a.objectAction(nil), nil
 	}
-	return a.chooseObject()
+	return navStay(), nil
 }
 
-// Display action for object
-func (a *App) objectAction() (bool, error) {
-	dir := ""
-	if len(a.prefix) > 0 {
-		dir = strings.Join(a.prefix, "/") + "/"
+// Go back to the location which is listed successfully lastly, or bucket list if nothing is listed yet
+func (a *App) restoreListed(screen *Screen) Navigation {
+	listed := screen.listed
+	if listed == nil || (listed.key() == a.currentVisit().key() && screen.versions == a.versions && screen.deleted == a.deleted) {
+		return a.toBucketList()
 	}
+	a.bucket, a.prefix = listed.bucket, append([]string{}, listed.prefix...)
+	a.versions, a.deleted = screen.versions, screen.deleted
+	return navStay()
+}
 
-	result, err := a.service.GetObject(&s3.GetObjectInput{
-		Bucket: aws.String(a.bucket),
-		Key:    aws.String(fmt.Sprintf("%s%s", dir, a.object)),
-	})
-	if err != nil {
-		return true, err
-	}
+// Show action menu for object, or specific version if supplied
+func (a *App) objectAction(version *Version) Navigation {
+	action := NewAction(a.service, a.bucket, a.dir()+a.object, a.selector, a.status, 2).WithVersion(version)
+	return navPush(&Screen{kind: ActionMenuScreen, action: action})
+}
 
+// Choose action in action menu
+func (a *App) chooseAction(action *Action) (Navigation, error) {
 	a.Clear()
 	a.writeHeader()
-	a.action = NewAction(result, a.object, a.selector, a.status, 2)
+	if !action.started() {
+		if err := action.Start(); err != nil {
+			return navPop(), err
+		}
+	}
+	a.action = action
 	defer func() {
 		a.action = nil
 	}()
-	return a.action.Do()
+	return action.Step()
+}
+
+// Display batch action for marked objects, or highlighted object if nothing is marked
+func (a *App) batchAction(objects Objects, index int, marked []int) error {
+	if len(marked) == 0 && index >= 0 {
+		marked = []int{index}
+	}
+	keys := []string{}
+	for _, i := range marked {
+		// Skip versions, they are out of objects range
+		if i >= len(objects) {
+			continue
+		}
+		o := objects[i]
+		switch {
+		case o.parent, o.deleted:
+			continue
+		case o.dir:
+			a.status.Message(fmt.Sprintf("Retriving objects under %s/ ...", o.key), 0)
+			dirKeys, err := listKeys(a.service, a.bucket, a.dir()+o.key+"/")
+			if err != nil {
+				return err
+			}
+			keys = append(keys, dirKeys...)
+		default:
+			keys = append(keys, a.dir()+o.key)
+		}
+	}
+
+	a.Clear()
+	a.writeHeader()
+	return NewBatch(a.service, a.bucket, keys, a.selector, a.status, 2).Do()
+}
+
+// Generate presigned PUT URL for uploading into current prefix
+func (a *App) uploadURL() error {
+	name, err := a.selector.Prompt("Upload object name: s3://"+a.bucket+"/"+a.dir(), "")
+	if err != nil || strings.TrimSpace(name) == "" {
+		return nil
+	}
+	a.Clear()
+	a.writeHeader()
+	expiry, ok, err := chooseExpiry("Upload link", a.selector, a.status)
+	if err != nil || !ok {
+		return err
+	}
+	url, err := presignPut(a.service, a.bucket, a.dir()+strings.TrimSpace(name), expiry)
+	if err != nil {
+		<-a.status.Failure("Failed to presign", err, 2)
+		return nil
+	}
+	showURL(url, expiry, a.selector, a.status)
+	return nil
+}
+
+// Undelete deleted object, or all objects under directory
+func (a *App) undeleteObject(selected *Object) error {
+	var prefix, message string
+	exact := false
+	switch {
+	case selected.parent:
+		return nil
+	case selected.dir:
+		prefix = a.dir() + selected.key + "/"
+		message = fmt.Sprintf("Undelete all objects under s3://%s/%s ?", a.bucket, prefix)
+	case selected.deleted:
+		prefix = a.dir() + selected.key
+		message = fmt.Sprintf("Undelete s3://%s/%s ?", a.bucket, prefix)
+		exact = true
+	default:
+		<-a.status.Warn(fmt.Sprintf("%s is not deleted", selected.key), 1)
+		return nil
+	}
+
+	a.Clear()
+	a.writeHeader()
+	if !a.selector.Confirm(message) {
+		return nil
+	}
+	a.status.Info("Undeleting...", 0)
+	count, err := undelete(a.service, a.bucket, prefix, exact)
+	if err != nil {
+		<-a.status.Failure("Failed to undelete", err, 2)
+		return nil
+	}
+	<-a.status.Info(fmt.Sprintf("Undeleted %d objects", count), 1)
+	return nil
 }
 
 // Filter and format object list
@@ -278,8 +647,46 @@ func formatObjects(s3Objects []*s3.Object, prefix []string) Objects {
 			key = parts[0]
 			isDir = true
 		}
-		objects = append(objects, NewObject(key, *o.Size, *o.LastModified, isDir))
+		objects = append(objects, NewObject(key, *o.Size, *o.LastModified, aws.StringValue(o.StorageClass), isDir))
 	}
 
 	return objects
 }
+
+// Merge objects which are hidden by latest delete marker into object list
+func formatDeletedObjects(objects Objects, s3Markers []*s3.DeleteMarkerEntry, prefix []string) Objects {
+	replace := ""
+	if len(prefix) > 0 {
+		replace = strings.Join(prefix, "/") + "/"
+	}
+	unique := map[string]struct{}{}
+	for _, o := range objects {
+		if o.dir {
+			unique[o.key] = struct{}{}
+		}
+	}
+	merged := append(Objects{}, objects...)
+	for _, d := range s3Markers {
+		if !*d.IsLatest {
+			continue
+		}
+		key := strings.Replace(*d.Key, replace, "", 1)
+
+		// If key contains "/", we deal with it as directory
+		if strings.Contains(key, "/") {
+			parts := strings.Split(key, "/")
+			if _, exist := unique[parts[0]]; exist {
+				continue
+			}
+			unique[parts[0]] = struct{}{}
+			merged = append(merged, NewDeletedObject(parts[0], *d.LastModified, true))
+			continue
+		}
+		merged = append(merged, NewDeletedObject(key, *d.LastModified, false))
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].key < merged[j].key
+	})
+
+	return merged
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestFormatDeletedObjects(t *testing.T) {
+	now := time.Now()
+	objects := Objects{
+		NewObject("bar", 0, now, "", true),
+		NewObject("a.txt", 1, now, "STANDARD", false),
+	}
+	s3Markers := []*s3.DeleteMarkerEntry{
+		{Key: aws.String("foo/b.txt"), VersionId: aws.String("m1"), IsLatest: aws.Bool(true), LastModified: aws.Time(now)},
+		{Key: aws.String("foo/c.txt"), VersionId: aws.String("m2"), IsLatest: aws.Bool(false), LastModified: aws.Time(now)},
+		{Key: aws.String("foo/bar/d.txt"), VersionId: aws.String("m3"), IsLatest: aws.Bool(true), LastModified: aws.Time(now)},
+		{Key: aws.String("foo/baz/e.txt"), VersionId: aws.String("m4"), IsLatest: aws.Bool(true), LastModified: aws.Time(now)},
+	}
+	merged := formatDeletedObjects(objects, s3Markers, []string{"foo"})
+	if len(merged) != 4 {
+		t.Fatalf("merged length expected 4, actual %d", len(merged))
+	}
+	expects := []struct {
+		key     string
+		deleted bool
+	}{
+		{"a.txt", false},
+		{"b.txt", true},
+		{"bar", false},
+		{"baz", true},
+	}
+	for i, e := range expects {
+		if merged[i].key != e.key || merged[i].deleted != e.deleted {
+			t.Errorf("index %d expected %s (deleted: %t), actual %s (deleted: %t)", i, e.key, e.deleted, merged[i].key, merged[i].deleted)
+		}
+	}
+}
+
+func TestBreadcrumbAt(t *testing.T) {
+	// Location: s3://bucket/foo/bar/object.txt
+	a := &App{bucket: "bucket", prefix: []string{"foo", "bar"}, object: "object.txt"}
+	tests := []struct {
+		x     int
+		depth int
+		ok    bool
+	}{
+		{0, 0, false},
+		{10, -1, true},
+		{14, -1, true},
+		{15, 0, true},
+		{21, 0, true},
+		{22, 1, true},
+		{26, 2, true},
+		{29, 2, true},
+		{30, 0, false},
+	}
+	for _, tt := range tests {
+		depth, ok := a.breadcrumbAt(tt.x)
+		if depth != tt.depth || ok != tt.ok {
+			t.Errorf("x=%d: expected (%d, %t), actual (%d, %t)", tt.x, tt.depth, tt.ok, depth, ok)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Batch action for multiple objects
+type Batch struct {
+
+	// S3 service instance
+	service *s3.S3
+
+	// Bucket name
+	bucket string
+
+	// Target object keys
+	keys []string
+
+	// Status Writer
+	status *Status
+
+	// Injected Selector
+	selector *Selector
+
+	// row offset for termbox
+	offset int
+}
+
+// Create Batch pointer
+func NewBatch(service *s3.S3, bucket string, keys []string, selector *Selector, status *Status, offset int) *Batch {
+	return &Batch{
+		service:  service,
+		bucket:   bucket,
+		keys:     keys,
+		selector: selector,
+		status:   status,
+		offset:   offset,
+	}
+}
+
+// Do batch action
+func (b *Batch) Do() error {
+	if len(b.keys) == 0 {
+		<-b.status.Warn("No objects to operate", 1)
+		return nil
+	}
+
+	actions := ActionList{
+		ActionCommand{op: Back, name: "Back To List"},
+		ActionCommand{op: EditMetadata, name: "Edit metadata"},
+		ActionCommand{op: EditTags, name: "Edit tags"},
+		ActionCommand{op: Transition, name: "Change storage class"},
+		ActionCommand{op: RestoreArchive, name: "Restore from archive"},
+	}
+	b.selector.SetOffset(b.offset).WithOutFilter()
+	b.status.Message(fmt.Sprintf("Choose batch action for %d objects", len(b.keys)), 0)
+	index, err := b.selector.Choose(actions.Selectable())
+	b.selector.WithFilter()
+	if err != nil || index < 0 {
+		return nil
+	}
+
+	switch actions[index].op {
+	case EditMetadata:
+		return b.doEditMetadata()
+	case EditTags:
+		return b.doEditTags()
+	case Transition:
+		return transitionObjects(b.service, b.bucket, b.keys, b.selector, b.status)
+	case RestoreArchive:
+		return restoreObjects(b.service, b.bucket, b.keys, b.selector, b.status)
+	default:
+		return nil
+	}
+}
+
+// Edit metadata of all target objects
+func (b *Batch) doEditMetadata() error {
+	change, ok, err := editMetadata(nil, b.selector, b.status)
+	if err != nil || !ok {
+		return err
+	}
+	return applyMetadata(b.service, b.bucket, b.keys, change, b.selector, b.status)
+}
+
+// List all object keys under prefix
+func listKeys(service *s3.S3, bucket, prefix string) ([]string, error) {
+	keys := []string{}
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	err := service.ListObjectsV2Pages(input, func(result *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, o := range result.Contents {
+			keys = append(keys, *o.Key)
+		}
+		return true
+	})
+	return keys, err
+}
+
+// Edit tags of all target objects
+func (b *Batch) doEditTags() error {
+	change, ok, err := editTags(nil, b.selector, b.status)
+	if err != nil || !ok {
+		return err
+	}
+	return applyTags(b.service, b.bucket, b.keys, change, b.selector, b.status)
+}
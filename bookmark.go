@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// Bookmarked location
+type Bookmark struct {
+
+	// Bookmark name
+	Name string `toml:"name"`
+
+	// Location URI like "s3://bucket/prefix/"
+	URI string `toml:"uri"`
+
+	// Profile name which is used to access the location
+	Profile string `toml:"profile,omitempty"`
+
+	// Region of the location
+	Region string `toml:"region,omitempty"`
+
+	Writer `toml:"-"`
+}
+
+// Writer::String implementation
+func (b *Bookmark) String() string {
+	return fmt.Sprintf("%-20s %s %s", b.Name, b.URI, b.access())
+}
+
+// Writer::Write implementation
+func (b *Bookmark) Write(y int, filter string) {
+	i := 0
+	first, last := findHighlightRange(b.Name, filter)
+	for j, r := range []rune(runewidth.FillRight(b.Name, 20) + " ") {
+		style := theme.Title
+		if j >= first && j < last {
+			style = theme.Highlight
+		}
+		termbox.SetCell(i, y, r, style.Fg, style.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+	for _, r := range []rune(b.URI + " ") {
+		termbox.SetCell(i, y, r, theme.Text.Fg, theme.Text.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+	for _, r := range []rune(b.access()) {
+		termbox.SetCell(i, y, r, theme.Muted.Fg, theme.Muted.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+}
+
+// Get profile and region for displaying
+func (b *Bookmark) access() string {
+	settings := []string{}
+	if b.Profile != "" {
+		settings = append(settings, "profile: "+b.Profile)
+	}
+	if b.Region != "" {
+		settings = append(settings, "region: "+b.Region)
+	}
+	if len(settings) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(settings, ", ") + ")"
+}
+
+// Define Bookmark slice type
+type Bookmarks []*Bookmark
+
+// Transform to Selectable type
+func (b Bookmarks) Selectable() Selectable {
+	s := Selectable{}
+	for _, v := range b {
+		s = append(s, v)
+	}
+	return s
+}
+
+// Find bookmark by name
+func (b Bookmarks) find(name string) *Bookmark {
+	for _, v := range b {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Add bookmark, bookmark which has the same name is replaced
+func (b Bookmarks) add(bookmark *Bookmark) Bookmarks {
+	for i, v := range b {
+		if v.Name == bookmark.Name {
+			replaced := append(Bookmarks{}, b...)
+			replaced[i] = bookmark
+			return replaced
+		}
+	}
+	return append(b, bookmark)
+}
+
+// Rename bookmark keeping its position
+func (b Bookmarks) rename(name, newName string) Bookmarks {
+	renamed := Bookmarks{}
+	for _, v := range b {
+		if v.Name == name {
+			r := *v
+			r.Name = newName
+			v = &r
+		}
+		renamed = append(renamed, v)
+	}
+	return renamed
+}
+
+// Remove bookmark by name
+func (b Bookmarks) remove(name string) Bookmarks {
+	removed := Bookmarks{}
+	for _, v := range b {
+		if v.Name != name {
+			removed = append(removed, v)
+		}
+	}
+	return removed
+}
+
+// Bookmark file struct
+type bookmarkFile struct {
+	Bookmarks Bookmarks `toml:"bookmarks"`
+}
+
+// Get bookmark file path in configuration directory
+func bookmarksPath() string {
+	return filepath.Join(configDir(), "bookmarks.toml")
+}
+
+// Load bookmarks, missing file is not an error
+func loadBookmarks(path string) (Bookmarks, error) {
+	file := bookmarkFile{}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Bookmarks{}, nil
+	}
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, err
+	}
+	return file.Bookmarks, nil
+}
+
+// Save bookmarks, configuration directory is created if not exists
+func saveBookmarks(path string, bookmarks Bookmarks) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	fp, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	return toml.NewEncoder(fp).Encode(bookmarkFile{Bookmarks: bookmarks})
+}
+
+// Bookmark current location by name
+func (a *App) addBookmark() {
+	name := a.bucket
+	if len(a.prefix) > 0 {
+		name = a.prefix[len(a.prefix)-1]
+	}
+	name, err := a.selector.Prompt("Bookmark name", name)
+	name = strings.TrimSpace(name)
+	if err != nil || name == "" {
+		return
+	}
+	path := bookmarksPath()
+	bookmarks, err := loadBookmarks(path)
+	if err != nil {
+		<-a.status.Error(fmt.Sprintf("Failed to load bookmarks: %s", err), 2)
+		return
+	}
+	bookmark := &Bookmark{
+		Name:    name,
+		URI:     "s3://" + a.bucket + "/" + a.dir(),
+		Profile: a.profile,
+		Region:  a.region,
+	}
+	if err := saveBookmarks(path, bookmarks.add(bookmark)); err != nil {
+		<-a.status.Error(fmt.Sprintf("Failed to save bookmarks: %s", err), 2)
+		return
+	}
+	<-a.status.Info(fmt.Sprintf("Bookmarked %s as %s", bookmark.URI, name), 1)
+}
+
+// Choose bookmark and go to the location
+func (a *App) chooseBookmark() {
+	path := bookmarksPath()
+	bookmarks, err := loadBookmarks(path)
+	if err != nil {
+		<-a.status.Error(fmt.Sprintf("Failed to load bookmarks: %s", err), 2)
+		return
+	}
+	if len(bookmarks) == 0 {
+		<-a.status.Warn("No bookmarks yet, add current location by "+keymap.Hint(CmdBookmark), 2)
+		return
+	}
+
+	a.Clear()
+	a.writeHeader()
+	a.status.Message("Choose bookmark ("+keymap.Hint(CmdActions)+")", 0)
+	a.selector.Bind(CmdActions)
+	index, err := a.selector.Choose(bookmarks.Selectable())
+	a.selector.Unbind()
+	if kerr, ok := err.(*KeyBindError); ok {
+		if kerr.Command == CmdActions && index >= 0 && index < len(bookmarks) {
+			a.bookmarkAction(path, bookmarks, bookmarks[index])
+		}
+		a.chooseBookmark()
+		return
+	} else if err != nil {
+		a.status.Clear()
+		return
+	}
+	a.status.Clear()
+	a.openBookmark(bookmarks[index])
+}
+
+// Rename or delete bookmark
+func (a *App) bookmarkAction(path string, bookmarks Bookmarks, bookmark *Bookmark) {
+	actions := ActionList{
+		ActionCommand{op: Back, name: "Back To List"},
+		ActionCommand{op: RenameBookmark, name: "Rename bookmark"},
+		ActionCommand{op: DeleteBookmark, name: "Delete bookmark"},
+	}
+	a.Clear()
+	a.writeHeader()
+	a.selector.WithOutFilter()
+	a.status.Message(fmt.Sprintf("Choose Action for bookmark %s", bookmark.Name), 0)
+	index, err := a.selector.Choose(actions.Selectable())
+	a.selector.WithFilter()
+	if err != nil || index < 0 {
+		return
+	}
+
+	switch actions[index].op {
+	case RenameBookmark:
+		name, err := a.selector.Prompt("New bookmark name", bookmark.Name)
+		name = strings.TrimSpace(name)
+		if err != nil || name == "" || name == bookmark.Name {
+			return
+		}
+		if bookmarks.find(name) != nil {
+			<-a.status.Warn(fmt.Sprintf("Bookmark %s already exists", name), 2)
+			return
+		}
+		bookmarks = bookmarks.rename(bookmark.Name, name)
+	case DeleteBookmark:
+		if !a.selector.Confirm(fmt.Sprintf("Delete bookmark %s ?", bookmark.Name)) {
+			return
+		}
+		bookmarks = bookmarks.remove(bookmark.Name)
+	default:
+		return
+	}
+	if err := saveBookmarks(path, bookmarks); err != nil {
+		<-a.status.Error(fmt.Sprintf("Failed to save bookmarks: %s", err), 2)
+	}
+}
+
+// Go to bookmarked location, S3 service is switched if profile or region is different
+func (a *App) openBookmark(bookmark *Bookmark) {
+	profile, region := bookmark.Profile, bookmark.Region
+	if profile == "" {
+		profile = a.profile
+	}
+	if region == "" {
+		region = a.region
+	}
+	if profile != a.profile || region != a.region {
+		a.service = newService(profile, region, profileEndpoint(profile))
+		a.profile, a.region = profile, region
+	}
+	a.bucket, a.prefix = parseBucketOption(bookmark.URI)
+	a.object = ""
+}
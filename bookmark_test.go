@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBookmarksSaveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ls3-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ls3", "bookmarks.toml")
+	bookmarks, err := loadBookmarks(path)
+	if err != nil || len(bookmarks) != 0 {
+		t.Fatalf("expected empty bookmarks for missing file, actual %v, %v", bookmarks, err)
+	}
+
+	bookmarks = bookmarks.add(&Bookmark{Name: "logs", URI: "s3://foo/logs/", Profile: "prod", Region: "us-east-1"})
+	bookmarks = bookmarks.add(&Bookmark{Name: "assets", URI: "s3://bar/"})
+	if err := saveBookmarks(path, bookmarks); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	loaded, err := loadBookmarks(path)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if !reflect.DeepEqual(loaded, bookmarks) {
+		t.Errorf("expected %v, actual %v", bookmarks, loaded)
+	}
+}
+
+func TestBookmarksModify(t *testing.T) {
+	bookmarks := Bookmarks{
+		{Name: "a", URI: "s3://a/"},
+		{Name: "b", URI: "s3://b/"},
+	}
+	replaced := bookmarks.add(&Bookmark{Name: "a", URI: "s3://a/x/"})
+	if len(replaced) != 2 || replaced[0].URI != "s3://a/x/" || bookmarks[0].URI != "s3://a/" {
+		t.Errorf("expected bookmark a is replaced without modifying original, actual %v", replaced)
+	}
+	renamed := bookmarks.rename("a", "c")
+	if renamed[0].Name != "c" || bookmarks[0].Name != "a" || bookmarks.find("c") != nil {
+		t.Errorf("expected bookmark a is renamed to c at same position, actual %v", renamed)
+	}
+	removed := bookmarks.remove("a")
+	if len(removed) != 1 || removed.find("a") != nil || removed.find("b") == nil {
+		t.Errorf("expected bookmark a is removed, actual %v", removed)
+	}
+}
+
+func TestProfileEndpoint(t *testing.T) {
+	saved := config
+	defer func() {
+		config = saved
+	}()
+
+	config = newConfig()
+	config.Profile = "local"
+	config.Endpoint = "http://localhost:9001"
+	config.Profiles = map[string]ProfileConfig{
+		"local": {Endpoint: "http://localhost:9000"},
+		"minio": {Endpoint: "http://minio:9000"},
+		"aws":   {Region: "us-east-1"},
+	}
+	tests := map[string]string{
+		"local":   "http://localhost:9001",
+		"minio":   "http://minio:9000",
+		"aws":     "http://localhost:9001",
+		"unknown": "http://localhost:9001",
+	}
+	for profile, expected := range tests {
+		if actual := profileEndpoint(profile); actual != expected {
+			t.Errorf("%s: expected %s, actual %s", profile, expected, actual)
+		}
+	}
+}
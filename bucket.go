@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/mattn/go-runewidth"
 	"github.com/nsf/termbox-go"
@@ -14,13 +16,17 @@ type Bucket struct {
 	// Bucket name
 	name string
 
+	// Bucket creation date
+	creationDate time.Time
+
 	Writer
 }
 
 // Create new bucket pointer
 func NewBucket(b *s3.Bucket) *Bucket {
 	return &Bucket{
-		name: *b.Name,
+		name:         *b.Name,
+		creationDate: aws.TimeValue(b.CreationDate),
 	}
 }
 
@@ -33,17 +39,17 @@ func (b *Bucket) String() string {
 func (b *Bucket) Write(y int, filter string) {
 	i := 0
 	for _, r := range []rune("[Bucket] ") {
-		termbox.SetCell(i, y, r, termbox.ColorCyan, termbox.ColorDefault)
+		termbox.SetCell(i, y, r, theme.Label.Fg, theme.Label.Bg)
 		i++
 	}
 
 	first, last := findHighlightRange(b.name, filter)
 	for j, r := range []rune(b.name) {
-		color := termbox.ColorWhite
+		style := theme.Text
 		if j >= first && j < last {
-			color = termbox.ColorYellow
+			style = theme.Highlight
 		}
-		termbox.SetCell(i, y, r, color, termbox.ColorDefault)
+		termbox.SetCell(i, y, r, style.Fg, style.Bg)
 		i += runewidth.RuneWidth(r)
 	}
 }
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// Bucket name rule: 3-63 characters of lowercase letters, numbers, dots and hyphens
+var bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// IPv4 address like bucket name is not allowed
+var ipAddressPattern = regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+$`)
+
+// Action for S3 bucket
+type BucketAction struct {
+
+	// S3 service instance
+	service *s3.S3
+
+	// Target bucket
+	bucket *Bucket
+
+	// Status Writer
+	status *Status
+
+	// Injected Selector
+	selector *Selector
+
+	// row offset for termbox
+	offset int
+}
+
+// Create BucketAction pointer
+func NewBucketAction(service *s3.S3, bucket *Bucket, selector *Selector, status *Status, offset int) *BucketAction {
+	return &BucketAction{
+		service:  service,
+		bucket:   bucket,
+		selector: selector,
+		status:   status,
+		offset:   offset,
+	}
+}
+
+// Do action
+func (b *BucketAction) Do() error {
+	actions := ActionList{
+		ActionCommand{op: Back, name: "Back To List"},
+		ActionCommand{op: Properties, name: "Show properties"},
+		ActionCommand{op: Lifecycle, name: "Edit lifecycle rules"},
+		ActionCommand{op: EditPolicy, name: "Edit bucket policy"},
+		ActionCommand{op: EditCors, name: "Edit CORS configuration"},
+		ActionCommand{op: DeleteBucket, name: "Delete bucket"},
+	}
+
+	b.selector.SetOffset(b.offset).WithOutFilter()
+	b.status.Message(fmt.Sprintf("Choose Action for bucket %s", b.bucket.name), 0)
+	index, err := b.selector.Choose(actions.Selectable())
+	b.selector.WithFilter()
+	if err != nil || index < 0 {
+		return nil
+	}
+
+	switch actions[index].op {
+	case Properties:
+		return NewBucketInfo(b.service, b.bucket, b.selector, b.status, b.offset).Do()
+	case Lifecycle:
+		return NewLifecycleEditor(b.service, b.bucket, b.selector, b.status, b.offset).Do()
+	case EditPolicy, EditCors:
+		service, ok := b.regionalService()
+		if !ok {
+			return nil
+		}
+		if actions[index].op == EditPolicy {
+			return editPolicy(service, b.bucket.name, b.selector, b.status)
+		}
+		return editCors(service, b.bucket.name, b.selector, b.status)
+	case DeleteBucket:
+		return b.doDelete()
+	default:
+		return nil
+	}
+}
+
+// Get S3 service for bucket region
+func (b *BucketAction) regionalService() (*s3.S3, bool) {
+	b.status.Message("Retriving bucket region...", 0)
+	region, err := s3manager.GetBucketRegionWithClient(aws.BackgroundContext(), b.service, b.bucket.name)
+	if err != nil {
+		<-b.status.Failure("Failed to get bucket region", err, 2)
+		return nil, false
+	}
+	return regionalService(b.service, region), true
+}
+
+// Delete bucket. Non-empty bucket needs typed bucket name to empty it
+func (b *BucketAction) doDelete() error {
+	service, ok := b.regionalService()
+	if !ok {
+		return nil
+	}
+
+	b.status.Message("Checking bucket is empty...", 0)
+	result, err := service.ListObjectVersions(&s3.ListObjectVersionsInput{
+		Bucket:  aws.String(b.bucket.name),
+		MaxKeys: aws.Int64(1),
+	})
+	if err != nil {
+		<-b.status.Failure("Failed to list objects", err, 2)
+		return nil
+	}
+
+	if len(result.Versions) == 0 && len(result.DeleteMarkers) == 0 {
+		b.selector.SetOffset(b.offset)
+		if !b.selector.Confirm(fmt.Sprintf("Delete empty bucket %s?", b.bucket.name)) {
+			return nil
+		}
+	} else {
+		// Prompt overwrites status row, so warning is shown as prompt message
+		message := fmt.Sprintf("Bucket is not empty! Type %s to delete ALL objects, versions and the bucket", b.bucket.name)
+		name, err := b.selector.Prompt(message, "")
+		if err != nil || name == "" {
+			return nil
+		}
+		if name != b.bucket.name {
+			<-b.status.Warn("Bucket name does not match, canceled", 1)
+			return nil
+		}
+		if err := emptyBucket(service, b.bucket.name, b.status); err != nil {
+			<-b.status.Failure("Failed to empty bucket", err, 2)
+			return nil
+		}
+	}
+
+	b.status.Info(fmt.Sprintf("Deleting bucket %s ...", b.bucket.name), 0)
+	if _, err := service.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(b.bucket.name)}); err != nil {
+		<-b.status.Failure("Failed to delete bucket", err, 2)
+		return nil
+	}
+	<-b.status.Info(fmt.Sprintf("Deleted bucket %s", b.bucket.name), 1)
+	return nil
+}
+
+// Delete all object versions, delete markers and multipart uploads in bucket
+func emptyBucket(service *s3.S3, bucket string, status *Status) error {
+	deleted := 0
+	var deleteErr error
+	input := &s3.ListObjectVersionsInput{Bucket: aws.String(bucket)}
+	err := service.ListObjectVersionsPages(input, func(result *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		targets := []*s3.ObjectIdentifier{}
+		for _, v := range result.Versions {
+			targets = append(targets, &s3.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, d := range result.DeleteMarkers {
+			targets = append(targets, &s3.ObjectIdentifier{Key: d.Key, VersionId: d.VersionId})
+		}
+		if len(targets) == 0 {
+			return true
+		}
+		// Page size is 1000 at most, it fits DeleteObjects limit
+		output, err := service.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &s3.Delete{
+				Objects: targets,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			deleteErr = err
+			return false
+		}
+		if len(output.Errors) > 0 {
+			deleteErr = fmt.Errorf("Failed to delete %s: %s", aws.StringValue(output.Errors[0].Key), aws.StringValue(output.Errors[0].Message))
+			return false
+		}
+		deleted += len(targets)
+		status.Info(fmt.Sprintf("Emptying bucket, deleted %d versions ...", deleted), 0)
+		return true
+	})
+	if deleteErr != nil {
+		return deleteErr
+	} else if err != nil {
+		return err
+	}
+
+	err = service.ListMultipartUploadsPages(&s3.ListMultipartUploadsInput{Bucket: aws.String(bucket)}, func(result *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+		for _, u := range result.Uploads {
+			if _, err := service.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(bucket),
+				Key:      u.Key,
+				UploadId: u.UploadId,
+			}); err != nil {
+				deleteErr = err
+				return false
+			}
+		}
+		return true
+	})
+	if deleteErr != nil {
+		return deleteErr
+	}
+	return err
+}
+
+// Validate bucket name
+func validateBucketName(name string) error {
+	switch {
+	case !bucketNamePattern.MatchString(name):
+		return fmt.Errorf("Bucket name must be 3-63 characters of lowercase letters, numbers, dots and hyphens")
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("Bucket name must not contain two adjacent periods")
+	case ipAddressPattern.MatchString(name):
+		return fmt.Errorf("Bucket name must not be formatted as an IP address")
+	case strings.HasPrefix(name, "xn--"), strings.HasSuffix(name, "-s3alias"):
+		return fmt.Errorf("Bucket name must not use reserved prefix or suffix")
+	}
+	return nil
+}
+
+// Create bucket with options by form
+func createBucket(service *s3.S3, selector *Selector, status *Status) error {
+	form := NewForm("Create bucket", selector, status)
+	form.Field("Name", "")
+	form.Field("Region", aws.StringValue(service.Config.Region))
+	form.Field("Versioning", "Disabled", "Disabled", "Enabled")
+	form.Field("Encryption", "SSE-S3", "SSE-S3", "SSE-KMS")
+	form.Field("KMS Key ID", "")
+	form.Field("Block Public Access", "Yes", "Yes", "No")
+
+	for {
+		saved, err := form.Do()
+		if err != nil || !saved {
+			return err
+		}
+		if err := validateBucketName(form.Value("Name")); err != nil {
+			<-status.Error(err.Error(), 2)
+			continue
+		}
+		if form.Value("Region") == "" {
+			<-status.Error("Region is required", 2)
+			continue
+		}
+		break
+	}
+
+	name := form.Value("Name")
+	region := form.Value("Region")
+	regional := regionalService(service, region)
+
+	status.Info(fmt.Sprintf("Creating bucket %s ...", name), 0)
+	input := &s3.CreateBucketInput{Bucket: aws.String(name)}
+	// us-east-1 must not be specified as location constraint
+	if region != "us-east-1" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(region),
+		}
+	}
+	if _, err := regional.CreateBucket(input); err != nil {
+		<-status.Failure("Failed to create bucket", err, 2)
+		return nil
+	}
+
+	if form.Value("Versioning") == "Enabled" {
+		if _, err := regional.PutBucketVersioning(&s3.PutBucketVersioningInput{
+			Bucket: aws.String(name),
+			VersioningConfiguration: &s3.VersioningConfiguration{
+				Status: aws.String(s3.BucketVersioningStatusEnabled),
+			},
+		}); err != nil {
+			<-status.Failure("Bucket created, but failed to enable versioning", err, 2)
+			return nil
+		}
+	}
+
+	encryption := &s3.ServerSideEncryptionByDefault{SSEAlgorithm: aws.String(s3.ServerSideEncryptionAes256)}
+	if form.Value("Encryption") == "SSE-KMS" {
+		encryption.SSEAlgorithm = aws.String(s3.ServerSideEncryptionAwsKms)
+		if key := form.Value("KMS Key ID"); key != "" {
+			encryption.KMSMasterKeyID = aws.String(key)
+		}
+	}
+	if _, err := regional.PutBucketEncryption(&s3.PutBucketEncryptionInput{
+		Bucket: aws.String(name),
+		ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+			Rules: []*s3.ServerSideEncryptionRule{
+				{ApplyServerSideEncryptionByDefault: encryption},
+			},
+		},
+	}); err != nil {
+		<-status.Failure("Bucket created, but failed to set encryption", err, 2)
+		return nil
+	}
+
+	var err error
+	if form.Value("Block Public Access") == "Yes" {
+		_, err = regional.PutPublicAccessBlock(&s3.PutPublicAccessBlockInput{
+			Bucket: aws.String(name),
+			PublicAccessBlockConfiguration: &s3.PublicAccessBlockConfiguration{
+				BlockPublicAcls:       aws.Bool(true),
+				IgnorePublicAcls:      aws.Bool(true),
+				BlockPublicPolicy:     aws.Bool(true),
+				RestrictPublicBuckets: aws.Bool(true),
+			},
+		})
+	} else {
+		_, err = regional.DeletePublicAccessBlock(&s3.DeletePublicAccessBlockInput{Bucket: aws.String(name)})
+	}
+	if err != nil {
+		<-status.Failure("Bucket created, but failed to set public access block", err, 2)
+		return nil
+	}
+
+	<-status.Info(fmt.Sprintf("Created bucket %s", name), 1)
+	return nil
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestValidateBucketName(t *testing.T) {
+	for _, name := range []string{"my-bucket", "logs.example.com", "a1b"} {
+		if err := validateBucketName(name); err != nil {
+			t.Errorf("%s expected valid, actual %s", name, err)
+		}
+	}
+	for _, name := range []string{"ab", "My-Bucket", "-bucket", "bucket-", "my..bucket", "192.168.0.1", "xn--bucket", "bucket_name"} {
+		if err := validateBucketName(name); err == nil {
+			t.Errorf("%s expected invalid", name)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// Error codes which mean the section is not configured
+var notConfiguredCodes = map[string]struct{}{
+	"NoSuchBucketPolicy":                             {},
+	"NoSuchCORSConfiguration":                        {},
+	"NoSuchLifecycleConfiguration":                   {},
+	"ReplicationConfigurationNotFoundError":          {},
+	"NoSuchWebsiteConfiguration":                     {},
+	"NoSuchTagSet":                                   {},
+	"ObjectLockConfigurationNotFoundError":           {},
+	"ServerSideEncryptionConfigurationNotFoundError": {},
+	"NoSuchPublicAccessBlockConfiguration":           {},
+}
+
+// Section loader which returns summary and detail lines
+type sectionLoader func(service *s3.S3, bucket string) (string, []string, error)
+
+// Bucket property section struct
+type BucketSection struct {
+
+	// Section name
+	name string
+
+	// Section loader
+	loader sectionLoader
+
+	// Loaded flag
+	loaded bool
+
+	// One line summary
+	summary string
+
+	// Detail lines
+	detail []string
+
+	// Loading error
+	err error
+
+	Writer
+}
+
+// Writer::String implementation
+func (b *BucketSection) String() string {
+	return fmt.Sprintf("%-20s: %s", b.name, b.display())
+}
+
+// Writer::Write implementation
+func (b *BucketSection) Write(y int, filter string) {
+	i := 0
+	for _, r := range []rune(fmt.Sprintf("%-20s: ", b.name)) {
+		termbox.SetCell(i, y, r, theme.Label.Fg, theme.Label.Bg)
+		i++
+	}
+	style := theme.Text
+	switch {
+	case !b.loaded:
+		style = theme.Muted
+	case b.err != nil && b.isNotConfigured():
+		style = theme.Notice
+	case b.err != nil:
+		style = theme.Danger
+	}
+	for _, r := range []rune(b.display()) {
+		termbox.SetCell(i, y, r, style.Fg, style.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+}
+
+// Get displaying summary
+func (b *BucketSection) display() string {
+	switch {
+	case !b.loaded:
+		return "(Enter to load)"
+	case b.err != nil && b.isNotConfigured():
+		return "(not configured)"
+	case b.err != nil:
+		return fmt.Sprintf("(%s)", errorCode(b.err))
+	default:
+		return b.summary
+	}
+}
+
+// Check error means the section is not configured
+func (b *BucketSection) isNotConfigured() bool {
+	_, ok := notConfiguredCodes[errorCode(b.err)]
+	return ok
+}
+
+// Load section if not loaded yet
+func (b *BucketSection) load(service *s3.S3, bucket string) {
+	if b.loaded {
+		return
+	}
+	b.summary, b.detail, b.err = b.loader(service, bucket)
+	b.loaded = true
+}
+
+// Bucket properties dashboard
+type BucketInfo struct {
+
+	// S3 service instance
+	service *s3.S3
+
+	// Target bucket
+	bucket *Bucket
+
+	// Property sections
+	sections []*BucketSection
+
+	// Status Writer
+	status *Status
+
+	// Injected Selector
+	selector *Selector
+
+	// row offset for termbox
+	offset int
+}
+
+// Create BucketInfo pointer
+func NewBucketInfo(service *s3.S3, bucket *Bucket, selector *Selector, status *Status, offset int) *BucketInfo {
+	creationDate := localTime(bucket.creationDate)
+	return &BucketInfo{
+		service:  service,
+		bucket:   bucket,
+		selector: selector,
+		status:   status,
+		offset:   offset,
+		sections: []*BucketSection{
+			{name: "Region", loader: loadRegion},
+			{name: "Creation Date", loaded: true, summary: creationDate, detail: []string{creationDate}},
+			{name: "Versioning", loader: loadVersioning},
+			{name: "Encryption", loader: loadEncryption},
+			{name: "Public Access Block", loader: loadPublicAccessBlock},
+			{name: "Policy", loader: loadPolicy},
+			{name: "ACL", loader: loadACL},
+			{name: "CORS", loader: loadCORS},
+			{name: "Lifecycle", loader: loadLifecycle},
+			{name: "Replication", loader: loadReplication},
+			{name: "Logging", loader: loadLogging},
+			{name: "Website", loader: loadWebsite},
+			{name: "Tags", loader: loadTags},
+			{name: "Object Lock", loader: loadObjectLock},
+			{name: "Requester Pays", loader: loadRequesterPays},
+		},
+	}
+}
+
+// Display dashboard, each section is loaded when chosen
+func (b *BucketInfo) Do() error {
+	// Bucket APIs must be called on the bucket region
+	b.status.Message("Retriving bucket region...", 0)
+	region, err := s3manager.GetBucketRegionWithClient(aws.BackgroundContext(), b.service, b.bucket.name)
+	if err != nil {
+		<-b.status.Failure("Failed to get bucket region", err, 2)
+		return nil
+	}
+	service := regionalService(b.service, region)
+
+	loadAll := ActionCommand{op: None, name: "[Load all sections]"}
+	for {
+		list := Selectable{loadAll}
+		for _, section := range b.sections {
+			list = append(list, section)
+		}
+
+		b.selector.SetOffset(b.offset).WithOutFilter()
+		b.status.Message(fmt.Sprintf("Properties of %s (Enter: load and show detail, Esc: back)", b.bucket.name), 0)
+		index, err := b.selector.Choose(list)
+		b.selector.WithFilter()
+		if err != nil || index < 0 {
+			return nil
+		}
+		if index == 0 {
+			for i, section := range b.sections {
+				b.status.Message(fmt.Sprintf("Loading %s (%d / %d) ...", section.name, i+1, len(b.sections)), 0)
+				section.load(service, b.bucket.name)
+			}
+			continue
+		}
+
+		section := b.sections[index-1]
+		b.status.Message(fmt.Sprintf("Loading %s ...", section.name), 0)
+		section.load(service, b.bucket.name)
+		detail := section.detail
+		if section.err != nil {
+			detail = []string{section.display(), section.err.Error()}
+		}
+		b.selector.Preview(detail, fmt.Sprintf("%s of %s (Enter/Esc: close)", section.name, b.bucket.name))
+	}
+}
+
+// Get S3 service for region
+func regionalService(service *s3.S3, region string) *s3.S3 {
+	if aws.StringValue(service.Config.Region) == region {
+		return service
+	}
+	return newClient(service.Config.Copy().WithRegion(region))
+}
+
+// Format API output as indented JSON lines
+func jsonLines(v interface{}) []string {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return strings.Split(string(buf), "\n")
+}
+
+// Format JSON string with indent
+func prettyJSON(src string) []string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(src), "", "  "); err != nil {
+		return strings.Split(src, "\n")
+	}
+	return strings.Split(buf.String(), "\n")
+}
+
+// Load region section
+func loadRegion(service *s3.S3, bucket string) (string, []string, error) {
+	region := aws.StringValue(service.Config.Region)
+	return region, []string{region}, nil
+}
+
+// Load versioning section
+func loadVersioning(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketVersioning(&s3.GetBucketVersioningInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	summary := "Disabled"
+	if result.Status != nil {
+		summary = *result.Status
+	}
+	if result.MFADelete != nil {
+		summary += ", MFA Delete " + *result.MFADelete
+	}
+	return summary, jsonLines(result), nil
+}
+
+// Load default encryption section
+func loadEncryption(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketEncryption(&s3.GetBucketEncryptionInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	algorithms := []string{}
+	for _, rule := range result.ServerSideEncryptionConfiguration.Rules {
+		if rule.ApplyServerSideEncryptionByDefault != nil {
+			algorithm := aws.StringValue(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+			if key := aws.StringValue(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID); key != "" {
+				algorithm += " (" + key + ")"
+			}
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+	return strings.Join(algorithms, ", "), jsonLines(result.ServerSideEncryptionConfiguration), nil
+}
+
+// Load public access block section
+func loadPublicAccessBlock(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetPublicAccessBlock(&s3.GetPublicAccessBlockInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	c := result.PublicAccessBlockConfiguration
+	flags := []bool{
+		aws.BoolValue(c.BlockPublicAcls),
+		aws.BoolValue(c.IgnorePublicAcls),
+		aws.BoolValue(c.BlockPublicPolicy),
+		aws.BoolValue(c.RestrictPublicBuckets),
+	}
+	blocked := 0
+	for _, f := range flags {
+		if f {
+			blocked++
+		}
+	}
+	summary := fmt.Sprintf("%d of 4 settings blocked", blocked)
+	if blocked == 4 {
+		summary = "All public access blocked"
+	}
+	return summary, jsonLines(c), nil
+}
+
+// Load bucket policy section
+func loadPolicy(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketPolicy(&s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	summary := "Configured"
+	if status, err := service.GetBucketPolicyStatus(&s3.GetBucketPolicyStatusInput{Bucket: aws.String(bucket)}); err == nil {
+		if aws.BoolValue(status.PolicyStatus.IsPublic) {
+			summary = "Configured (public)"
+		} else {
+			summary = "Configured (not public)"
+		}
+	}
+	return summary, prettyJSON(aws.StringValue(result.Policy)), nil
+}
+
+// Load ACL grants section
+func loadACL(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketAcl(&s3.GetBucketAclInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	detail := []string{fmt.Sprintf("Owner: %s (%s)", aws.StringValue(result.Owner.DisplayName), aws.StringValue(result.Owner.ID))}
+	for _, g := range result.Grants {
+		grantee := aws.StringValue(g.Grantee.DisplayName)
+		switch {
+		case g.Grantee.URI != nil:
+			grantee = *g.Grantee.URI
+		case g.Grantee.EmailAddress != nil:
+			grantee = *g.Grantee.EmailAddress
+		case grantee == "":
+			grantee = aws.StringValue(g.Grantee.ID)
+		}
+		detail = append(detail, fmt.Sprintf("Grant: %s -> %s", aws.StringValue(g.Permission), grantee))
+	}
+	return fmt.Sprintf("%d grants", len(result.Grants)), detail, nil
+}
+
+// Load CORS rules section
+func loadCORS(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketCors(&s3.GetBucketCorsInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%d rules", len(result.CORSRules)), jsonLines(result.CORSRules), nil
+}
+
+// Load lifecycle rules section
+func loadLifecycle(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%d rules", len(result.Rules)), jsonLines(result.Rules), nil
+}
+
+// Load replication rules section
+func loadReplication(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketReplication(&s3.GetBucketReplicationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	c := result.ReplicationConfiguration
+	return fmt.Sprintf("%d rules", len(c.Rules)), jsonLines(c), nil
+}
+
+// Load access logging section
+func loadLogging(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketLogging(&s3.GetBucketLoggingInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	if result.LoggingEnabled == nil {
+		return "Disabled", []string{"Disabled"}, nil
+	}
+	l := result.LoggingEnabled
+	return fmt.Sprintf("s3://%s/%s", aws.StringValue(l.TargetBucket), aws.StringValue(l.TargetPrefix)), jsonLines(l), nil
+}
+
+// Load website configuration section
+func loadWebsite(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketWebsite(&s3.GetBucketWebsiteInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	summary := "Enabled"
+	if result.IndexDocument != nil {
+		summary += ", index: " + aws.StringValue(result.IndexDocument.Suffix)
+	}
+	if result.RedirectAllRequestsTo != nil {
+		summary = "Redirect to " + aws.StringValue(result.RedirectAllRequestsTo.HostName)
+	}
+	return summary, jsonLines(result), nil
+}
+
+// Load bucket tags section
+func loadTags(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketTagging(&s3.GetBucketTaggingInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	tags := tagsFromSet(result.TagSet)
+	detail := []string{}
+	for _, k := range tags.keys() {
+		detail = append(detail, fmt.Sprintf("%s = %s", k, tags[k]))
+	}
+	return fmt.Sprintf("%d tags", len(tags)), detail, nil
+}
+
+// Load object lock configuration section
+func loadObjectLock(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetObjectLockConfiguration(&s3.GetObjectLockConfigurationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	c := result.ObjectLockConfiguration
+	summary := aws.StringValue(c.ObjectLockEnabled)
+	if c.Rule != nil && c.Rule.DefaultRetention != nil {
+		r := c.Rule.DefaultRetention
+		summary += fmt.Sprintf(", default %s %d days %d years", aws.StringValue(r.Mode), aws.Int64Value(r.Days), aws.Int64Value(r.Years))
+	}
+	return summary, jsonLines(c), nil
+}
+
+// Load requester pays section
+func loadRequesterPays(service *s3.S3, bucket string) (string, []string, error) {
+	result, err := service.GetBucketRequestPayment(&s3.GetBucketRequestPaymentInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", nil, err
+	}
+	payer := aws.StringValue(result.Payer)
+	return payer, []string{"Payer: " + payer}, nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Allowed CORS methods
+var corsMethods = map[string]struct{}{
+	"GET":    {},
+	"PUT":    {},
+	"POST":   {},
+	"DELETE": {},
+	"HEAD":   {},
+}
+
+// Max CORS rules per bucket
+const maxCorsRules = 100
+
+// CORS configuration XML document
+type corsDocument struct {
+	XMLName xml.Name   `xml:"CORSConfiguration"`
+	Rules   []corsRule `xml:"CORSRule"`
+}
+
+// CORS rule XML element
+type corsRule struct {
+	ID             string   `xml:"ID,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	AllowedMethods []string `xml:"AllowedMethod"`
+	AllowedHeaders []string `xml:"AllowedHeader"`
+	ExposeHeaders  []string `xml:"ExposeHeader"`
+	MaxAgeSeconds  *int64   `xml:"MaxAgeSeconds,omitempty"`
+}
+
+// Format CORS rules as XML document
+func formatCors(rules []*s3.CORSRule) string {
+	doc := corsDocument{}
+	for _, r := range rules {
+		doc.Rules = append(doc.Rules, corsRule{
+			ID:             aws.StringValue(r.ID),
+			AllowedOrigins: aws.StringValueSlice(r.AllowedOrigins),
+			AllowedMethods: aws.StringValueSlice(r.AllowedMethods),
+			AllowedHeaders: aws.StringValueSlice(r.AllowedHeaders),
+			ExposeHeaders:  aws.StringValueSlice(r.ExposeHeaders),
+			MaxAgeSeconds:  r.MaxAgeSeconds,
+		})
+	}
+	buf, _ := xml.MarshalIndent(doc, "", "  ")
+	return string(buf) + "\n"
+}
+
+// Parse and validate CORS XML document
+func parseCors(src string) ([]*s3.CORSRule, error) {
+	rules := []*s3.CORSRule{}
+	if strings.TrimSpace(src) == "" {
+		return rules, nil
+	}
+	doc := corsDocument{}
+	if err := xml.Unmarshal([]byte(src), &doc); err != nil {
+		return nil, fmt.Errorf("Invalid XML: %s", err)
+	}
+	if len(doc.Rules) > maxCorsRules {
+		return nil, fmt.Errorf("CORS configuration can have %d rules at most", maxCorsRules)
+	}
+	for i, r := range doc.Rules {
+		if len(r.AllowedOrigins) == 0 || len(r.AllowedMethods) == 0 {
+			return nil, fmt.Errorf("CORSRule #%d needs AllowedOrigin and AllowedMethod", i+1)
+		}
+		for _, method := range r.AllowedMethods {
+			if _, ok := corsMethods[method]; !ok {
+				return nil, fmt.Errorf("CORSRule #%d has unsupported method %s", i+1, method)
+			}
+		}
+		rule := &s3.CORSRule{
+			AllowedOrigins: aws.StringSlice(r.AllowedOrigins),
+			AllowedMethods: aws.StringSlice(r.AllowedMethods),
+			MaxAgeSeconds:  r.MaxAgeSeconds,
+		}
+		if r.ID != "" {
+			rule.ID = aws.String(r.ID)
+		}
+		if len(r.AllowedHeaders) > 0 {
+			rule.AllowedHeaders = aws.StringSlice(r.AllowedHeaders)
+		}
+		if len(r.ExposeHeaders) > 0 {
+			rule.ExposeHeaders = aws.StringSlice(r.ExposeHeaders)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Validate bucket policy JSON document, empty document means deleting policy
+func validatePolicy(src string) error {
+	if strings.TrimSpace(src) == "" {
+		return nil
+	}
+	var policy struct {
+		Statement json.RawMessage
+	}
+	if err := json.Unmarshal([]byte(src), &policy); err != nil {
+		return fmt.Errorf("Invalid JSON: %s", err)
+	}
+	if len(policy.Statement) == 0 {
+		return fmt.Errorf("Policy must have Statement")
+	}
+	return nil
+}
+
+// Edit document by $EDITOR until it is valid, and confirm with diff
+func editDocument(name, current string, validate func(string) error, selector *Selector, status *Status) (string, bool) {
+	content := current
+	for {
+		edited, err := editContent(selector, name, []byte(content))
+		if err != nil {
+			<-status.Error(err.Error(), 2)
+			return "", false
+		}
+		content = string(edited)
+		if content == current {
+			<-status.Warn("Nothing to change", 1)
+			return "", false
+		}
+		if err := validate(content); err != nil {
+			if selector.Confirm(fmt.Sprintf("%s. Edit again?", err)) {
+				continue
+			}
+			return "", false
+		}
+		message := "Apply changes? (Enter: apply, Esc: cancel)"
+		if strings.TrimSpace(content) == "" {
+			message = "Document is empty, it will be deleted. Apply? (Enter: apply, Esc: cancel)"
+		}
+		if !selector.Preview(diffLines(current, content), message) {
+			return "", false
+		}
+		return content, true
+	}
+}
+
+// Edit bucket policy in $EDITOR
+func editPolicy(service *s3.S3, bucket string, selector *Selector, status *Status) error {
+	status.Message("Retriving bucket policy...", 0)
+	current := ""
+	result, err := service.GetBucketPolicy(&s3.GetBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+	switch {
+	case errorCode(err) == "NoSuchBucketPolicy":
+		break
+	case err != nil:
+		<-status.Failure("Failed to get bucket policy", err, 2)
+		return nil
+	default:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(aws.StringValue(result.Policy)), "", "  "); err != nil {
+			current = aws.StringValue(result.Policy) + "\n"
+		} else {
+			current = buf.String() + "\n"
+		}
+	}
+
+	policy, ok := editDocument(bucket+"-policy.json", current, validatePolicy, selector, status)
+	if !ok {
+		return nil
+	}
+	status.Info("Saving bucket policy...", 0)
+	if strings.TrimSpace(policy) == "" {
+		_, err = service.DeleteBucketPolicy(&s3.DeleteBucketPolicyInput{
+			Bucket: aws.String(bucket),
+		})
+	} else {
+		_, err = service.PutBucketPolicy(&s3.PutBucketPolicyInput{
+			Bucket: aws.String(bucket),
+			Policy: aws.String(policy),
+		})
+	}
+	if err != nil {
+		<-status.Failure("Failed to save bucket policy", err, 2)
+		return nil
+	}
+	<-status.Info("Saved bucket policy", 1)
+	return nil
+}
+
+// Edit CORS configuration in $EDITOR as XML document
+func editCors(service *s3.S3, bucket string, selector *Selector, status *Status) error {
+	status.Message("Retriving CORS configuration...", 0)
+	current := ""
+	result, err := service.GetBucketCors(&s3.GetBucketCorsInput{
+		Bucket: aws.String(bucket),
+	})
+	switch {
+	case errorCode(err) == "NoSuchCORSConfiguration":
+		break
+	case err != nil:
+		<-status.Failure("Failed to get CORS configuration", err, 2)
+		return nil
+	default:
+		current = formatCors(result.CORSRules)
+	}
+
+	validate := func(src string) error {
+		_, err := parseCors(src)
+		return err
+	}
+	src, ok := editDocument(bucket+"-cors.xml", current, validate, selector, status)
+	if !ok {
+		return nil
+	}
+	rules, _ := parseCors(src)
+	status.Info("Saving CORS configuration...", 0)
+	if len(rules) == 0 {
+		_, err = service.DeleteBucketCors(&s3.DeleteBucketCorsInput{
+			Bucket: aws.String(bucket),
+		})
+	} else {
+		_, err = service.PutBucketCors(&s3.PutBucketCorsInput{
+			Bucket: aws.String(bucket),
+			CORSConfiguration: &s3.CORSConfiguration{
+				CORSRules: rules,
+			},
+		})
+	}
+	if err != nil {
+		<-status.Failure("Failed to save CORS configuration", err, 2)
+		return nil
+	}
+	<-status.Info("Saved CORS configuration", 1)
+	return nil
+}
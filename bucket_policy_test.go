@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestParseCors(t *testing.T) {
+	rules := []*s3.CORSRule{
+		{
+			AllowedOrigins: aws.StringSlice([]string{"https://example.com"}),
+			AllowedMethods: aws.StringSlice([]string{"GET", "PUT"}),
+			AllowedHeaders: aws.StringSlice([]string{"*"}),
+			MaxAgeSeconds:  aws.Int64(3000),
+		},
+	}
+	parsed, err := parseCors(formatCors(rules))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(parsed) != 1 || len(parsed[0].AllowedMethods) != 2 || aws.Int64Value(parsed[0].MaxAgeSeconds) != 3000 {
+		t.Errorf("expected same rules after round trip, actual %v", parsed)
+	}
+	if parsed, err := parseCors("  \n"); err != nil || len(parsed) != 0 {
+		t.Errorf("expected empty rules, actual %v, %v", parsed, err)
+	}
+
+	for _, src := range []string{
+		"<CORSConfiguration><CORSRule>",
+		"<CORSConfiguration><CORSRule><AllowedMethod>GET</AllowedMethod></CORSRule></CORSConfiguration>",
+		"<CORSConfiguration><CORSRule><AllowedOrigin>*</AllowedOrigin><AllowedMethod>PATCH</AllowedMethod></CORSRule></CORSConfiguration>",
+	} {
+		if _, err := parseCors(src); err == nil {
+			t.Errorf("expected error for %s", src)
+		}
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	if err := validatePolicy(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow"}]}`); err != nil {
+		t.Errorf("expected valid policy, actual %s", err)
+	}
+	if err := validatePolicy(""); err != nil {
+		t.Errorf("expected empty policy is valid for deletion, actual %s", err)
+	}
+	if err := validatePolicy(`{"Version":"2012-10-17"`); err == nil {
+		t.Errorf("expected invalid JSON error")
+	}
+	if err := validatePolicy(`{"Version":"2012-10-17"}`); err == nil {
+		t.Errorf("expected missing Statement error")
+	}
+}
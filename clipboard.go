@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+)
+
+// Copy text to clipboard via OSC 52 escape sequence, which works over SSH
+func copyToClipboard(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+
+	_, err = tty.WriteString(osc52(text, os.Getenv("TMUX") != "", strings.HasPrefix(os.Getenv("TERM"), "screen")))
+	return err
+}
+
+// Make OSC 52 sequence, wrap it with passthrough sequence for tmux or screen
+func osc52(text string, tmux, screen bool) string {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	switch {
+	case tmux:
+		return "\x1bPtmux;" + strings.Replace(sequence, "\x1b", "\x1b\x1b", -1) + "\x1b\\"
+	case screen:
+		return "\x1bP" + sequence + "\x1b\\"
+	default:
+		return sequence
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestOsc52(t *testing.T) {
+	if seq := osc52("hello", false, false); seq != "\x1b]52;c;aGVsbG8=\x07" {
+		t.Errorf("unexpected sequence %q", seq)
+	}
+	if seq := osc52("hello", true, false); seq != "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\x07\x1b\\" {
+		t.Errorf("unexpected tmux sequence %q", seq)
+	}
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,13 +21,9 @@ type Writer interface {
 // Selectable slice type
 type Selectable []Writer
 
-// We're living in Asia/Tokyo location :)
-var JST = time.FixedZone("Asia/Tokyo", 9*60*60)
-
-// Transform from UTC to JST
-func utcToJst(utc time.Time) string {
-	jst := utc.In(JST)
-	return jst.Format("2006-01-02 15:03:04")
+// Format time in configured timezone
+func localTime(t time.Time) string {
+	return t.In(location).Format("2006-01-02 15:04:05")
 }
 
 // Find and get highlight range
@@ -40,3 +43,111 @@ func findHighlightRange(haystack, needle string) (first, last int) {
 
 	return
 }
+
+// Make URL encoded copy source for CopyObject
+func copySource(bucket, key, versionId string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	source := bucket + "/" + strings.Join(parts, "/")
+	if versionId != "" {
+		source += "?versionId=" + url.QueryEscape(versionId)
+	}
+	return source
+}
+
+// Get error code from AWS error
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code()
+	}
+	return err.Error()
+}
+
+// Run job for each index by configured concurrency. Progress is called with done count after each job
+// on the caller goroutine, so it can draw on screen. Returns the first failed index and error,
+// remaining jobs are skipped after failure
+func parallel(total int, job func(i int) error, progress func(done int)) (int, error) {
+	var mutex sync.Mutex
+	failed := -1
+	var failure error
+
+	indexes := make(chan int)
+	finished := make(chan struct{})
+	for w := 0; w < config.Concurrency; w++ {
+		go func() {
+			for i := range indexes {
+				mutex.Lock()
+				skip := failure != nil
+				mutex.Unlock()
+				if !skip {
+					if err := job(i); err != nil {
+						mutex.Lock()
+						if failure == nil {
+							failed, failure = i, err
+						}
+						mutex.Unlock()
+					}
+				}
+				finished <- struct{}{}
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < total; i++ {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+	for done := 1; done <= total; done++ {
+		<-finished
+		progress(done)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	return failed, failure
+}
+
+// Get longest common prefix of strings
+func commonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	prefix := []rune(values[0])
+	for _, v := range values[1:] {
+		runes := []rune(v)
+		i := 0
+		for i < len(prefix) && i < len(runes) && prefix[i] == runes[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return string(prefix)
+}
+
+// Write reader content to file through temporary file in the same directory,
+// so existing file is not truncated and no partial file is left on failure
+func writeFile(writePath string, r io.Reader) error {
+	fp, err := ioutil.TempFile(filepath.Dir(writePath), "."+filepath.Base(writePath)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(fp, r); err == nil {
+		err = fp.Chmod(0644)
+	}
+	if closeErr := fp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(fp.Name(), writePath)
+	}
+	if err != nil {
+		os.Remove(fp.Name())
+	}
+	return err
+}
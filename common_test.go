@@ -1,6 +1,14 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -13,3 +21,84 @@ func TestFindHighlightRange(t *testing.T) {
 		t.Errorf("last expected 11, actual %d", last)
 	}
 }
+
+func TestCopySource(t *testing.T) {
+	source := copySource("bucket", "foo/bar baz.txt", "v1")
+	if source != "bucket/foo/bar%20baz.txt?versionId=v1" {
+		t.Errorf("unexpected copy source %s", source)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	var sum int32
+	progress := 0
+	failed, err := parallel(10, func(i int) error {
+		atomic.AddInt32(&sum, int32(i))
+		return nil
+	}, func(done int) {
+		// Progress is called on caller goroutine in order
+		if done != progress+1 {
+			t.Errorf("expected progress %d, actual %d", progress+1, done)
+		}
+		progress = done
+	})
+	if err != nil || failed != -1 || sum != 45 || progress != 10 {
+		t.Errorf("unexpected result %d, %v, sum %d, progress %d", failed, err, sum, progress)
+	}
+
+	failed, err = parallel(10, func(i int) error {
+		if i == 3 {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	}, func(done int) {})
+	if err == nil || failed != 3 {
+		t.Errorf("expected failure at 3, actual %d, %v", failed, err)
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		values   []string
+		expected string
+	}{
+		{[]string{}, ""},
+		{[]string{"foo/"}, "foo/"},
+		{[]string{"foo/bar/", "foo/baz.txt"}, "foo/ba"},
+		{[]string{"abc", "xyz"}, ""},
+	}
+	for _, tt := range tests {
+		if actual := commonPrefix(tt.values); actual != tt.expected {
+			t.Errorf("%v: expected %s, actual %s", tt.values, tt.expected, actual)
+		}
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ls3-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "file.txt")
+	if err := writeFile(path, strings.NewReader("original")); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	// Failed write keeps existing file and leaves no temporary file
+	if err := writeFile(path, io.MultiReader(strings.NewReader("partial"), failingReader{})); err == nil {
+		t.Errorf("expected error")
+	}
+	if content, _ := ioutil.ReadFile(path); string(content) != "original" {
+		t.Errorf("expected existing file is kept, actual %s", content)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("expected temporary file is removed, actual %d files", len(files))
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Default values when neither flags nor configuration file specify
+const (
+	defaultRegion      = "ap-northeast-1"
+	defaultTimezone    = "Asia/Tokyo"
+	defaultConcurrency = 4
+	defaultRetries     = 5
+)
+
+// Settings which can be overridden per profile
+type ProfileConfig struct {
+
+	// AWS region
+	Region string `toml:"region"`
+
+	// S3 compatible endpoint URL, e.g. MinIO
+	Endpoint string `toml:"endpoint"`
+
+	// Timezone name for displaying time, e.g. "UTC", "Local", "America/New_York"
+	Timezone string `toml:"timezone"`
+
+	// Download directory, current working directory is used if empty
+	DownloadDir string `toml:"download_dir"`
+
+	// Number of parallel requests for batch operations
+	Concurrency int `toml:"concurrency"`
+}
+
+// Configuration file struct
+type Config struct {
+	ProfileConfig
+
+	// Default profile name in ~/.aws/credentials
+	Profile string `toml:"profile"`
+
+	// Color theme name, built-in "dark", "light", "high-contrast" or name in themes
+	Theme string `toml:"theme"`
+
+	// Color mode, "auto", "none", "8", "256" or "truecolor"
+	ColorMode string `toml:"color_mode"`
+
+	// Custom themes by name, e.g. [themes.solarized]. Values are style specs by color slot
+	Themes map[string]map[string]string `toml:"themes"`
+
+	// Capture mouse for clicking and scrolling, disable to select text by terminal
+	Mouse bool `toml:"mouse"`
+
+	// Max retry count for throttling and server errors
+	Retries int `toml:"retries"`
+
+	// Keymap preset name, "default", "vim" or "emacs"
+	Keymap string `toml:"keymap"`
+
+	// Key bindings by command name which override preset
+	Keys map[string]KeyList `toml:"keys"`
+
+	// External program commands by file extension (".png") or MIME type ("image/*")
+	Openers map[string]string `toml:"openers"`
+
+	// Settings per profile name, e.g. [profiles.production]
+	Profiles map[string]ProfileConfig `toml:"profiles"`
+}
+
+// Key specs which accept both a string and an array of strings
+type KeyList []string
+
+// toml::Unmarshaler implementation
+func (k *KeyList) UnmarshalTOML(v interface{}) error {
+	switch value := v.(type) {
+	case string:
+		*k = KeyList{value}
+	case []interface{}:
+		list := KeyList{}
+		for _, item := range value {
+			spec, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("key must be string")
+			}
+			list = append(list, spec)
+		}
+		*k = list
+	default:
+		return fmt.Errorf("key must be string or array of strings")
+	}
+	return nil
+}
+
+// Loaded configuration
+var config = newConfig()
+
+// Timezone location for displaying time
+var location = time.FixedZone(defaultTimezone, 9*60*60)
+
+// Create configuration with default values
+func newConfig() *Config {
+	return &Config{
+		ProfileConfig: ProfileConfig{
+			Region:      defaultRegion,
+			Timezone:    defaultTimezone,
+			Concurrency: defaultConcurrency,
+		},
+		Mouse:    true,
+		Retries:  defaultRetries,
+		Keys:     map[string]KeyList{},
+		Themes:   map[string]map[string]string{},
+		Openers:  map[string]string{},
+		Profiles: map[string]ProfileConfig{},
+	}
+}
+
+// Get configuration directory, $XDG_CONFIG_HOME/ls3 or ~/.config/ls3
+func configDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ls3")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "ls3")
+}
+
+// Get configuration file path
+func configPath() string {
+	return filepath.Join(configDir(), "config.toml")
+}
+
+// Load configuration file, missing file is not an error
+func loadConfig(path string) (*Config, error) {
+	c := newConfig()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return c, nil
+	}
+	meta, err := toml.DecodeFile(path, c)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown key %s", undecoded[0])
+	}
+	return c, nil
+}
+
+// Apply profile section over top level settings. Empty profile name means "default"
+func (c *Config) UseProfile(name string) {
+	if name == "" {
+		name = "default"
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return
+	}
+	if p.Region != "" {
+		c.Region = p.Region
+	}
+	if p.Endpoint != "" {
+		c.Endpoint = p.Endpoint
+	}
+	if p.Timezone != "" {
+		c.Timezone = p.Timezone
+	}
+	if p.DownloadDir != "" {
+		c.DownloadDir = p.DownloadDir
+	}
+	if p.Concurrency > 0 {
+		c.Concurrency = p.Concurrency
+	}
+}
+
+// Validate settings, then setup timezone location, keymap and theme
+func (c *Config) Apply() error {
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be greater than 0")
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	overrides := map[string][]string{}
+	for name, keys := range c.Keys {
+		overrides[name] = keys
+	}
+	km, err := NewKeymap(c.Keymap, overrides)
+	if err != nil {
+		return err
+	}
+	mode, err := detectColorMode(c.ColorMode)
+	if err != nil {
+		return err
+	}
+	th, err := NewTheme(c.Theme, c.Themes, mode)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		// Keep working without timezone database for default timezone
+		if c.Timezone != defaultTimezone {
+			return fmt.Errorf("unknown timezone %s", c.Timezone)
+		}
+		loc = location
+	}
+	location = loc
+	keymap = km
+	theme = th
+	return nil
+}
+
+// Get download directory, "~/" is expanded to home directory
+func (c *Config) downloadDir() string {
+	dir := c.DownloadDir
+	if dir == "" {
+		cwd, _ := os.Getwd()
+		return cwd
+	}
+	if strings.HasPrefix(dir, "~/") {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, dir[2:])
+	}
+	return dir
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ls3-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := loadConfig(filepath.Join(dir, "missing.toml"))
+	if err != nil || c.Region != defaultRegion || c.Concurrency != defaultConcurrency {
+		t.Errorf("missing file expected default config, actual %v, %v", c, err)
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	ioutil.WriteFile(path, []byte(`
+profile = "staging"
+region = "us-west-2"
+timezone = "UTC"
+
+[openers]
+".png" = "feh"
+
+[keys]
+sort = "S"
+mark = ["space", "tab"]
+
+[profiles.staging]
+region = "eu-west-1"
+endpoint = "http://localhost:9000"
+`), 0644)
+	c, err = loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if c.Openers[".png"] != "feh" || c.Region != "us-west-2" || c.Concurrency != defaultConcurrency {
+		t.Errorf("unexpected config %v", c)
+	}
+	if len(c.Keys["sort"]) != 1 || len(c.Keys["mark"]) != 2 {
+		t.Errorf("expected keys by string and array, actual %v", c.Keys)
+	}
+	c.UseProfile(c.Profile)
+	if c.Region != "eu-west-1" || c.Endpoint != "http://localhost:9000" || c.Timezone != "UTC" {
+		t.Errorf("profile section expected to override, actual %v", c)
+	}
+
+	ioutil.WriteFile(path, []byte("regoin = \"us-east-1\"\n"), 0644)
+	if _, err := loadConfig(path); err == nil {
+		t.Errorf("unknown key expected error")
+	}
+}
+
+func TestConfigApply(t *testing.T) {
+	savedLocation, savedKeymap, savedTheme := location, keymap, theme
+	defer func() {
+		location, keymap, theme = savedLocation, savedKeymap, savedTheme
+	}()
+
+	c := newConfig()
+	c.Concurrency = 0
+	if err := c.Apply(); err == nil {
+		t.Errorf("zero concurrency expected error")
+	}
+	c = newConfig()
+	c.Retries = -1
+	if err := c.Apply(); err == nil {
+		t.Errorf("negative retries expected error")
+	}
+	c = newConfig()
+	c.Timezone = "Unknown/Zone"
+	if err := c.Apply(); err == nil {
+		t.Errorf("unknown timezone expected error")
+	}
+	c = newConfig()
+	c.Timezone = "UTC"
+	if err := c.Apply(); err != nil || location.String() != "UTC" {
+		t.Errorf("expected UTC location, actual %s, %v", location, err)
+	}
+}
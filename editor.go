@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Max object size which can be edited in $EDITOR
+const maxEditSize = 1024 * 1024
+
+// Max cells for LCS table of diff, larger changes are displayed as replacing whole block
+const maxDiffCells = 4000000
+
+// Get editor command from $VISUAL or $EDITOR, fallback to vi
+func editorCommand() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(name); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// Edit content with $EDITOR on temporary file, name is used for file extension
+func editContent(selector *Selector, name string, content []byte) ([]byte, error) {
+	file, err := ioutil.TempFile("", "ls3-*-"+strings.Replace(name, "/", "_", -1))
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	err = selector.Suspend(func() error {
+		// Run via shell in order to accept editor arguments, e.g. EDITOR="code -w"
+		cmd := exec.Command("sh", "-c", editorCommand()+` "$1"`, "ls3", file.Name())
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Editor exited with error: %s", err)
+	}
+	return ioutil.ReadFile(file.Name())
+}
+
+// Make line based diff of two texts, each line is prefixed with "  ", "- " or "+ "
+func diffLines(before, after string) []string {
+	a := strings.Split(strings.TrimRight(before, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(after, "\n"), "\n")
+	if before == "" {
+		a = []string{}
+	}
+	if after == "" {
+		b = []string{}
+	}
+
+	// Strip common head and tail
+	head := 0
+	for head < len(a) && head < len(b) && a[head] == b[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(a)-head && tail < len(b)-head && a[len(a)-1-tail] == b[len(b)-1-tail] {
+		tail++
+	}
+
+	lines := []string{}
+	for _, line := range a[:head] {
+		lines = append(lines, "  "+line)
+	}
+	lines = append(lines, diffBlock(a[head:len(a)-tail], b[head:len(b)-tail])...)
+	for _, line := range a[len(a)-tail:] {
+		lines = append(lines, "  "+line)
+	}
+	return lines
+}
+
+// Diff changed block by LCS
+func diffBlock(a, b []string) []string {
+	lines := []string{}
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			lines = append(lines, "- "+line)
+		}
+		for _, line := range b {
+			lines = append(lines, "+ "+line)
+		}
+		return lines
+	}
+
+	// lcs[i][j] is LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+ "+b[j])
+	}
+	return lines
+}
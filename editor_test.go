@@ -0,0 +1,21 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	before := "a\nb\nc\nd\n"
+	after := "a\nc\nx\nd\n"
+	expected := []string{"  a", "- b", "  c", "+ x", "  d"}
+	if actual := diffLines(before, after); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, actual %v", expected, actual)
+	}
+	if actual := diffLines("", "a\n"); !reflect.DeepEqual(actual, []string{"+ a"}) {
+		t.Errorf("expected added line, actual %v", actual)
+	}
+	if actual := diffLines("a\n", ""); !reflect.DeepEqual(actual, []string{"- a"}) {
+		t.Errorf("expected removed line, actual %v", actual)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// Form field struct
+type FormField struct {
+
+	// Field label
+	label string
+
+	// Field value
+	value string
+
+	// Selectable options, free text input if empty
+	options []string
+
+	// Displays instead of value when field is not changed
+	placeholder string
+
+	// Changed flag
+	changed bool
+
+	// Removed flag of removable form. Otherwise changed empty value means removing
+	removed bool
+
+	// Field belongs to removable form, empty value is kept as is
+	removable bool
+
+	Writer
+}
+
+// Writer::String implementation
+func (f *FormField) String() string {
+	return fmt.Sprintf("%-24s: %s", f.label, f.display())
+}
+
+// Writer::Write implementation
+func (f *FormField) Write(y int, filter string) {
+	i := 0
+	for _, r := range []rune(fmt.Sprintf("%-24s: ", f.label)) {
+		termbox.SetCell(i, y, r, theme.Label.Fg, theme.Label.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+	style := theme.Text
+	if f.changed {
+		style = theme.Notice
+	} else if f.placeholder != "" {
+		style = theme.Muted
+	}
+	for _, r := range []rune(f.display()) {
+		termbox.SetCell(i, y, r, style.Fg, style.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+}
+
+// Get displaying value
+func (f *FormField) display() string {
+	switch {
+	case !f.changed && f.placeholder != "":
+		return f.placeholder
+	case f.removed:
+		return "(remove)"
+	case f.changed && f.value == "" && !f.removable:
+		return "(remove)"
+	case f.value == "" && f.removable:
+		return `""`
+	default:
+		return f.value
+	}
+}
+
+// Form struct which edits fields on selector
+type Form struct {
+
+	// Form title
+	title string
+
+	// Form fields
+	fields []*FormField
+
+	// Allow user to add new fields
+	extendable bool
+
+	// Label prefix for fields which user adds
+	prefix string
+
+	// Allow user to remove fields by button instead of empty value
+	removable bool
+
+	// Status Writer
+	status *Status
+
+	// Injected Selector
+	selector *Selector
+}
+
+// Create Form pointer
+func NewForm(title string, selector *Selector, status *Status) *Form {
+	return &Form{
+		title:    title,
+		fields:   []*FormField{},
+		selector: selector,
+		status:   status,
+	}
+}
+
+// Add field to form
+func (f *Form) Field(label, value string, options ...string) *FormField {
+	field := &FormField{
+		label:     label,
+		value:     value,
+		options:   options,
+		removable: f.removable,
+	}
+	f.fields = append(f.fields, field)
+	return field
+}
+
+// Allow user to add new fields with label prefix
+func (f *Form) Extendable(prefix string) *Form {
+	f.extendable = true
+	f.prefix = prefix
+	return f
+}
+
+// Allow user to remove fields by button, then empty value is a valid value
+func (f *Form) Removable() *Form {
+	f.removable = true
+	for _, field := range f.fields {
+		field.removable = true
+	}
+	return f
+}
+
+// Get field by label
+func (f *Form) Get(label string) *FormField {
+	for _, field := range f.fields {
+		if field.label == label {
+			return field
+		}
+	}
+	return nil
+}
+
+// Get value by label
+func (f *Form) Value(label string) string {
+	if field := f.Get(label); field != nil {
+		return field.value
+	}
+	return ""
+}
+
+// Edit form fields, returns true when saved
+func (f *Form) Do() (bool, error) {
+	f.selector.WithOutFilter()
+	defer f.selector.WithFilter()
+
+	for {
+		list := Selectable{}
+		for _, field := range f.fields {
+			list = append(list, field)
+		}
+		buttons := ActionList{}
+		if f.extendable {
+			buttons = append(buttons, ActionCommand{op: AddField, name: "[+] Add field"})
+		}
+		if f.removable {
+			buttons = append(buttons, ActionCommand{op: RemoveField, name: "[-] Remove field"})
+		}
+		buttons = append(buttons,
+			ActionCommand{op: Save, name: "[Save]"},
+			ActionCommand{op: Back, name: "[Cancel]"},
+		)
+		for _, b := range buttons {
+			list = append(list, b)
+		}
+
+		f.status.Message(f.title, 0)
+		index, err := f.selector.Choose(list)
+		if err != nil || index < 0 {
+			return false, nil
+		}
+		if index < len(f.fields) {
+			if err := f.edit(f.fields[index]); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		switch buttons[index-len(f.fields)].op {
+		case AddField:
+			if err := f.add(); err != nil {
+				return false, err
+			}
+		case RemoveField:
+			f.remove()
+		case Save:
+			return true, nil
+		default:
+			return false, nil
+		}
+	}
+}
+
+// Edit field value by prompt or options
+func (f *Form) edit(field *FormField) error {
+	if len(field.options) == 0 {
+		value, err := f.selector.Prompt(field.label, field.value)
+		if err != nil {
+			// Canceled input, keep current value
+			return nil
+		}
+		field.value = strings.TrimSpace(value)
+		field.changed = true
+		field.removed = false
+		return nil
+	}
+
+	options := ActionList{}
+	for _, o := range field.options {
+		options = append(options, ActionCommand{op: None, name: o})
+	}
+	f.status.Message(fmt.Sprintf("Choose %s", field.label), 0)
+	index, err := f.selector.Choose(options.Selectable())
+	if err != nil || index < 0 {
+		return nil
+	}
+	field.value = field.options[index]
+	field.changed = true
+	field.removed = false
+	return nil
+}
+
+// Add new field by prompt
+func (f *Form) add() error {
+	name, err := f.selector.Prompt("Field name: "+f.prefix, "")
+	if err != nil || strings.TrimSpace(name) == "" {
+		return nil
+	}
+	label := f.prefix + strings.TrimSpace(name)
+	field := f.Get(label)
+	if field == nil {
+		field = f.Field(label, "")
+	}
+	return f.edit(field)
+}
+
+// Choose field and mark it as removed. Extendable form can remove field by name which is not in form
+func (f *Form) remove() {
+	list := Selectable{}
+	for _, field := range f.fields {
+		list = append(list, field)
+	}
+	if f.extendable {
+		list = append(list, ActionCommand{op: AddField, name: "[Enter field name]"})
+	}
+	f.status.Message("Choose field to remove", 0)
+	index, err := f.selector.Choose(list)
+	if err != nil || index < 0 || index >= len(list) {
+		return
+	}
+
+	var field *FormField
+	if index < len(f.fields) {
+		field = f.fields[index]
+	} else {
+		name, err := f.selector.Prompt("Field name: "+f.prefix, "")
+		if err != nil || strings.TrimSpace(name) == "" {
+			return
+		}
+		label := f.prefix + strings.TrimSpace(name)
+		if field = f.Get(label); field == nil {
+			field = f.Field(label, "")
+		}
+	}
+	field.removed = true
+	field.changed = true
+}
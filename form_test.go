@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFormFieldDisplay(t *testing.T) {
+	form := NewForm("title", nil, nil)
+	header := form.Field("Cache-Control", "")
+	header.changed = true
+	if actual := header.display(); actual != "(remove)" {
+		t.Errorf("changed empty value expected to remove, actual %s", actual)
+	}
+
+	form = NewForm("title", nil, nil).Removable()
+	tag := form.Field("env", "prod")
+	tag.value = ""
+	tag.changed = true
+	if actual := tag.display(); actual != `""` {
+		t.Errorf("empty value of removable form expected to be kept, actual %s", actual)
+	}
+	tag.removed = true
+	if actual := tag.display(); actual != "(remove)" {
+		t.Errorf("removed field expected to remove, actual %s", actual)
+	}
+}
@@ -1,11 +1,12 @@
 module github.com/ysugimoto/ls3
 
-go 1.12
+go 1.19
 
 require (
-	github.com/aws/aws-sdk-go v1.10.15
-	github.com/go-ini/ini v1.28.1
-	github.com/jmespath/go-jmespath v0.0.0-20160803190731-bd40a432e4c7
-	github.com/mattn/go-runewidth v0.0.2
-	github.com/nsf/termbox-go v0.0.0-20170710103407-4ed959e05409
+	github.com/BurntSushi/toml v1.4.0
+	github.com/aws/aws-sdk-go v1.55.8
+	github.com/mattn/go-runewidth v0.0.9
+	github.com/nsf/termbox-go v1.1.1
 )
+
+require github.com/jmespath/go-jmespath v0.4.0 // indirect
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Bucket and key which path input points to. Key is empty for bucket root, and ends with "/" for prefix
+type s3Path struct {
+	bucket string
+	key    string
+}
+
+// Split slash separated path into segments, empty segments are dropped
+func splitPath(p string) []string {
+	segments := []string{}
+	for _, s := range strings.Split(p, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// Parse "bucket/key" form
+func bucketPath(p string) s3Path {
+	p = strings.TrimPrefix(p, "/")
+	if i := strings.Index(p, "/"); i >= 0 {
+		return s3Path{bucket: p[:i], key: strings.TrimLeft(p[i+1:], "/")}
+	}
+	return s3Path{bucket: p}
+}
+
+// Parse bucket option which accepts bucket name or URI like "s3://bucket/prefix/"
+func parseBucketOption(value string) (string, []string) {
+	segments := splitPath(strings.TrimPrefix(value, "s3://"))
+	if len(segments) == 0 {
+		return "", []string{}
+	}
+	return segments[0], segments[1:]
+}
+
+// Make candidate paths of input. "s3://bucket/key" is absolute, "/key" is from root of current bucket,
+// and others are relative to current prefix. Relative path may be "bucket/key" form as well,
+// so it's the second candidate
+func pathCandidates(input, bucket string, prefix []string) ([]s3Path, error) {
+	if strings.HasPrefix(input, "s3://") {
+		p := bucketPath(strings.TrimPrefix(input, "s3://"))
+		if p.bucket == "" {
+			return nil, fmt.Errorf("bucket name is empty")
+		}
+		return []s3Path{p}, nil
+	}
+	if bucket == "" {
+		p := bucketPath(input)
+		if p.bucket == "" {
+			return nil, fmt.Errorf("bucket name is empty")
+		}
+		return []s3Path{p}, nil
+	}
+
+	dir := ""
+	if len(prefix) > 0 && !strings.HasPrefix(input, "/") {
+		dir = strings.Join(prefix, "/") + "/"
+	}
+	// Leading slash prevents ".." from going above bucket root
+	key := strings.TrimPrefix(path.Clean("/"+dir+input), "/")
+	if key != "" && (input == "" || strings.HasSuffix(input, "/")) {
+		key += "/"
+	}
+	candidates := []s3Path{{bucket: bucket, key: key}}
+	if !strings.HasPrefix(input, "/") && !strings.HasPrefix(input, ".") && input != "" {
+		candidates = append(candidates, bucketPath(input))
+	}
+	return candidates, nil
+}
+
+// Check path exists, and returns true if key names an object rather than prefix
+func locate(service *s3.S3, p s3Path) (bool, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(p.bucket),
+		MaxKeys: aws.Int64(1),
+	}
+	if p.key == "" || strings.HasSuffix(p.key, "/") {
+		if p.key != "" {
+			input = input.SetPrefix(p.key)
+		}
+		result, err := service.ListObjectsV2(input)
+		if err != nil {
+			return false, err
+		}
+		if p.key != "" && len(result.Contents) == 0 {
+			return false, fmt.Errorf("No such prefix %s", p.key)
+		}
+		return false, nil
+	}
+
+	// Prefix is preferred when both prefix and object exist
+	result, err := service.ListObjectsV2(input.SetPrefix(p.key + "/"))
+	if err != nil {
+		return false, err
+	}
+	if len(result.Contents) > 0 {
+		return false, nil
+	}
+	if _, err := service.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(p.key),
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Complete path input by bucket names or keys under the prefix
+func (a *App) completePath(input string) []string {
+	rest := strings.TrimPrefix(input, "s3://")
+	scheme := input[:len(input)-len(rest)]
+	if (scheme != "" || a.bucket == "") && !strings.Contains(rest, "/") {
+		result, err := a.service.ListBuckets(&s3.ListBucketsInput{})
+		if err != nil {
+			return nil
+		}
+		completions := []string{}
+		for _, b := range result.Buckets {
+			if name := aws.StringValue(b.Name); strings.HasPrefix(name, rest) {
+				completions = append(completions, scheme+name+"/")
+			}
+		}
+		return completions
+	}
+
+	// Dot segment can't be completed by listing
+	if base := path.Base(input); !strings.HasSuffix(input, "/") && (base == "." || base == "..") {
+		return nil
+	}
+	candidates, err := pathCandidates(input, a.bucket, a.prefix)
+	if err != nil {
+		return nil
+	}
+	p := candidates[0]
+	dir := p.key[:strings.LastIndex(p.key, "/")+1]
+	typed := input[:strings.LastIndex(input, "/")+1]
+
+	listInput := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(p.bucket),
+		Delimiter: aws.String("/"),
+		MaxKeys:   aws.Int64(1000),
+	}
+	if p.key != "" {
+		listInput = listInput.SetPrefix(p.key)
+	}
+	result, err := a.service.ListObjectsV2(listInput)
+	if err != nil {
+		return nil
+	}
+	completions := []string{}
+	for _, cp := range result.CommonPrefixes {
+		completions = append(completions, typed+strings.TrimPrefix(aws.StringValue(cp.Prefix), dir))
+	}
+	for _, o := range result.Contents {
+		if key := aws.StringValue(o.Key); key != dir {
+			completions = append(completions, typed+strings.TrimPrefix(key, dir))
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// Prompt path and go to the bucket and prefix. Object name is kept in open to show its action menu
+func (a *App) goTo() {
+	input, err := a.selector.PromptWithCompletion("Go to", "", a.completePath)
+	input = strings.TrimSpace(input)
+	if err != nil || input == "" {
+		return
+	}
+	candidates, err := pathCandidates(input, a.bucket, a.prefix)
+	if err != nil {
+		<-a.status.Error(fmt.Sprintf("Invalid path %s: %s", input, err), 2)
+		return
+	}
+
+	a.status.Message(fmt.Sprintf("Looking up %s ...", input), 0)
+	for _, p := range candidates {
+		isObject, lerr := locate(a.service, p)
+		if lerr != nil {
+			err = lerr
+			continue
+		}
+		a.bucket = p.bucket
+		a.prefix = splitPath(p.key)
+		a.object = ""
+		if isObject {
+			a.open = a.prefix[len(a.prefix)-1]
+			a.prefix = a.prefix[0 : len(a.prefix)-1]
+		}
+		a.status.Clear()
+		return
+	}
+	<-a.status.Failure(fmt.Sprintf("Failed to go to %s", input), err, 2)
+}
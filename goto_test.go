@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathCandidates(t *testing.T) {
+	tests := []struct {
+		input    string
+		bucket   string
+		prefix   []string
+		expected []s3Path
+	}{
+		{"s3://foo/a/b/", "bar", []string{"x"}, []s3Path{{"foo", "a/b/"}}},
+		{"s3://foo", "", []string{}, []s3Path{{"foo", ""}}},
+		{"foo/a/b", "", []string{}, []s3Path{{"foo", "a/b"}}},
+		{"/a/b/", "bar", []string{"x"}, []s3Path{{"bar", "a/b/"}}},
+		{"a/b", "bar", []string{"x"}, []s3Path{{"bar", "x/a/b"}, {"a", "b"}}},
+		{"../y/", "bar", []string{"x", "z"}, []s3Path{{"bar", "x/y/"}}},
+		{"../../..", "bar", []string{"x"}, []s3Path{{"bar", ""}}},
+		{"", "bar", []string{"x"}, []s3Path{{"bar", "x/"}}},
+	}
+	for _, tt := range tests {
+		actual, err := pathCandidates(tt.input, tt.bucket, tt.prefix)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%s: expected %v, actual %v", tt.input, tt.expected, actual)
+		}
+	}
+	for _, input := range []string{"s3://", "/"} {
+		if _, err := pathCandidates(input, "", []string{}); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestParseBucketOption(t *testing.T) {
+	tests := map[string][]string{
+		"":              {""},
+		"foo":           {"foo"},
+		"s3://foo":      {"foo"},
+		"s3://foo/a/b/": {"foo", "a", "b"},
+		"s3://foo//a/b": {"foo", "a", "b"},
+		"foo/a":         {"foo", "a"},
+	}
+	for value, expected := range tests {
+		bucket, prefix := parseBucketOption(value)
+		actual := append([]string{bucket}, prefix...)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %v, actual %v", value, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// Get help title from the kind of listed items
+func helpTitle(list Selectable) string {
+	if len(list) == 0 {
+		return "Key bindings"
+	}
+	switch list[0].(type) {
+	case *Bucket:
+		return "Key bindings: bucket list"
+	case *Object, *Version:
+		return "Key bindings: object list"
+	case ActionCommand:
+		return "Key bindings: action menu"
+	case *TextLine:
+		return "Key bindings: viewer"
+	}
+	return "Key bindings"
+}
+
+// Make help overlay lines of active key bindings
+func (s *Selector) helpLines() []string {
+	rows := keymap.Help(s.isEnabled)
+	width := 0
+	for _, row := range rows {
+		if w := runewidth.StringWidth(row[0]); w > width {
+			width = w
+		}
+	}
+	lines := []string{}
+	for _, row := range rows {
+		lines = append(lines, fmt.Sprintf("%s  %s", runewidth.FillRight(row[0], width), row[1]))
+	}
+	if s.enableFilter && keymap.instantFilter {
+		lines = append(lines, fmt.Sprintf("%s  %s", runewidth.FillRight("(type)", width), "Filter list"))
+	}
+	return lines
+}
+
+// Display modal overlay over the list, closed by any key
+func (s *Selector) displayOverlay(state *SelectorState) {
+	switch state.overlay {
+	case CmdHelp:
+		s.displayBox(helpTitle(state.items), s.helpLines())
+	case CmdErrorDetails:
+		s.displayBox("Error details", s.status.Details())
+	}
+}
+
+// Display box of title and lines at the center of selector area
+func (s *Selector) displayBox(title string, lines []string) {
+	footer := "Press any key to close"
+
+	width := runewidth.StringWidth(title)
+	for _, line := range append(lines, footer) {
+		if w := runewidth.StringWidth(line); w > width {
+			width = w
+		}
+	}
+	// Border and padding
+	boxWidth := width + 4
+	if boxWidth > s.width {
+		boxWidth = s.width
+	}
+	boxHeight := len(lines) + 4
+	if limit := s.height - s.offset; boxHeight > limit {
+		if limit < 5 {
+			return
+		}
+		boxHeight = limit
+		lines = lines[:boxHeight-4]
+	}
+	left := (s.width - boxWidth) / 2
+	top := s.offset + (s.height-s.offset-boxHeight)/2
+
+	border := theme.Label
+	for y := top; y < top+boxHeight; y++ {
+		for x := left; x < left+boxWidth; x++ {
+			r := ' '
+			switch {
+			case (y == top || y == top+boxHeight-1) && (x == left || x == left+boxWidth-1):
+				r = '+'
+			case y == top || y == top+boxHeight-1:
+				r = '-'
+			case x == left || x == left+boxWidth-1:
+				r = '|'
+			}
+			termbox.SetCell(x, y, r, border.Fg, border.Bg)
+		}
+	}
+
+	write := func(y int, text string, style Style) {
+		x := left + 2
+		for _, r := range text {
+			if x+runewidth.RuneWidth(r) > left+boxWidth-2 {
+				break
+			}
+			termbox.SetCell(x, y, r, style.Fg, style.Bg)
+			x += runewidth.RuneWidth(r)
+		}
+	}
+	write(top, " "+title+" ", theme.Title)
+	for i, line := range lines {
+		write(top+1+i, line, theme.Text)
+	}
+	write(top+boxHeight-2, footer, theme.Muted)
+	termbox.Flush()
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestHelpTitle(t *testing.T) {
+	tests := map[string]Selectable{
+		"Key bindings":              {},
+		"Key bindings: bucket list": {&Bucket{name: "foo"}},
+		"Key bindings: object list": {NewParentObject()},
+		"Key bindings: action menu": {ActionCommand{name: "Back"}},
+		"Key bindings: viewer":      {&TextLine{number: 1}},
+	}
+	for expected, list := range tests {
+		if actual := helpTitle(list); actual != expected {
+			t.Errorf("expected %s, actual %s", expected, actual)
+		}
+	}
+}
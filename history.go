@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// Max amount of persisted recent locations
+const maxRecentLocations = 30
+
+// Visited location in bucket
+type Visit struct {
+	bucket string
+	prefix []string
+}
+
+// Get location key
+func (v Visit) key() string {
+	if len(v.prefix) == 0 {
+		return v.bucket + "/"
+	}
+	return v.bucket + "/" + strings.Join(v.prefix, "/") + "/"
+}
+
+// Back and forward history of visited locations like a browser
+type NavHistory struct {
+
+	// Visited locations
+	visits []Visit
+
+	// Current position in visits
+	index int
+
+	// Cursor and filter by location key
+	views map[string]ListView
+
+	// Location key which is recorded to recent locations lastly
+	recorded string
+}
+
+// Create empty history
+func NewNavHistory() *NavHistory {
+	return &NavHistory{
+		index: -1,
+		views: make(map[string]ListView),
+	}
+}
+
+// Record visiting location. Forward history is dropped unless location is current one
+func (h *NavHistory) visit(v Visit) {
+	if h.index >= 0 && h.visits[h.index].key() == v.key() {
+		return
+	}
+	h.visits = append(h.visits[0:h.index+1], Visit{bucket: v.bucket, prefix: append([]string{}, v.prefix...)})
+	h.index++
+}
+
+// Get current location
+func (h *NavHistory) current() (Visit, bool) {
+	if h.index < 0 {
+		return Visit{}, false
+	}
+	return h.visits[h.index], true
+}
+
+// Go back to previous location
+func (h *NavHistory) back() (Visit, bool) {
+	if h.index <= 0 {
+		return Visit{}, false
+	}
+	h.index--
+	return h.visits[h.index], true
+}
+
+// Go forward to next location
+func (h *NavHistory) forward() (Visit, bool) {
+	if h.index+1 >= len(h.visits) {
+		return Visit{}, false
+	}
+	h.index++
+	return h.visits[h.index], true
+}
+
+// Save cursor and filter of location
+func (h *NavHistory) saveView(v Visit, view ListView) {
+	h.views[v.key()] = view
+}
+
+// Get saved cursor and filter of location
+func (h *NavHistory) view(v Visit) (ListView, bool) {
+	view, ok := h.views[v.key()]
+	return view, ok
+}
+
+// Recently visited location which is persisted across sessions
+type RecentLocation struct {
+
+	// Location URI like "s3://bucket/prefix/"
+	URI string `toml:"uri"`
+
+	// Profile name which is used to access the location
+	Profile string `toml:"profile,omitempty"`
+
+	// Region of the location
+	Region string `toml:"region,omitempty"`
+
+	// Last visited time
+	Visited time.Time `toml:"visited"`
+
+	Writer `toml:"-"`
+}
+
+// Writer::String implementation
+func (r *RecentLocation) String() string {
+	return fmt.Sprintf("%s %s %s", localTime(r.Visited), r.URI, r.bookmark().access())
+}
+
+// Writer::Write implementation
+func (r *RecentLocation) Write(y int, filter string) {
+	i := 0
+	for _, c := range []rune(localTime(r.Visited) + " ") {
+		termbox.SetCell(i, y, c, theme.Date.Fg, theme.Date.Bg)
+		i++
+	}
+	first, last := findHighlightRange(r.URI, filter)
+	for j, c := range []rune(r.URI + " ") {
+		style := theme.Text
+		if j >= first && j < last {
+			style = theme.Highlight
+		}
+		termbox.SetCell(i, y, c, style.Fg, style.Bg)
+		i += runewidth.RuneWidth(c)
+	}
+	for _, c := range []rune(r.bookmark().access()) {
+		termbox.SetCell(i, y, c, theme.Muted.Fg, theme.Muted.Bg)
+		i += runewidth.RuneWidth(c)
+	}
+}
+
+// Make unnamed bookmark for opening the location
+func (r *RecentLocation) bookmark() *Bookmark {
+	return &Bookmark{URI: r.URI, Profile: r.Profile, Region: r.Region}
+}
+
+// Define RecentLocation slice type
+type RecentLocations []*RecentLocation
+
+// Transform to Selectable type
+func (r RecentLocations) Selectable() Selectable {
+	s := Selectable{}
+	for _, v := range r {
+		s = append(s, v)
+	}
+	return s
+}
+
+// Put location at first, the same location is moved and old locations are dropped
+func (r RecentLocations) add(location *RecentLocation) RecentLocations {
+	added := RecentLocations{location}
+	for _, v := range r {
+		if len(added) >= maxRecentLocations {
+			break
+		}
+		if v.URI == location.URI && v.Profile == location.Profile && v.Region == location.Region {
+			continue
+		}
+		added = append(added, v)
+	}
+	return added
+}
+
+// Recent locations file struct
+type recentFile struct {
+	Locations RecentLocations `toml:"locations"`
+}
+
+// Get recent locations file path in configuration directory
+func recentPath() string {
+	return filepath.Join(configDir(), "recent.toml")
+}
+
+// Load recent locations, missing file is not an error
+func loadRecent(path string) (RecentLocations, error) {
+	file := recentFile{}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return RecentLocations{}, nil
+	}
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, err
+	}
+	return file.Locations, nil
+}
+
+// Save recent locations, configuration directory is created if not exists
+func saveRecent(path string, locations RecentLocations) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	fp, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	return toml.NewEncoder(fp).Encode(recentFile{Locations: locations})
+}
+
+// Get current location
+func (a *App) currentVisit() Visit {
+	return Visit{bucket: a.bucket, prefix: a.prefix}
+}
+
+// Record current location into history and recent locations. Bucket list is not a recent location
+func (a *App) recordVisit() {
+	visit := a.currentVisit()
+	a.history.visit(visit)
+	if a.bucket == "" || a.history.recorded == visit.key() {
+		return
+	}
+	a.history.recorded = visit.key()
+
+	path := recentPath()
+	locations, err := loadRecent(path)
+	if err != nil {
+		logger.log(fmt.Sprintf("Failed to load recent locations: %s", err))
+		return
+	}
+	locations = locations.add(&RecentLocation{
+		URI:     "s3://" + a.bucket + "/" + a.dir(),
+		Profile: a.profile,
+		Region:  a.region,
+		Visited: time.Now(),
+	})
+	if err := saveRecent(path, locations); err != nil {
+		logger.log(fmt.Sprintf("Failed to save recent locations: %s", err))
+	}
+}
+
+// Restore cursor and filter of current location. Cursor is moved to the child name which we came back from
+func (a *App) restoreView(names []string) {
+	view, ok := a.history.view(a.currentVisit())
+	if a.child != "" {
+		for i, name := range names {
+			if name == a.child {
+				view.Cursor = i
+				ok = true
+				break
+			}
+		}
+		a.child = ""
+	}
+	if ok {
+		a.selector.WithView(view)
+	}
+}
+
+// Go to location in history
+func (a *App) goHistory(v Visit, ok bool) {
+	if !ok {
+		<-a.status.Warn("No more history", 1)
+		return
+	}
+	a.bucket = v.bucket
+	a.prefix = append([]string{}, v.prefix...)
+	a.object = ""
+}
+
+// Choose recent location and go to there
+func (a *App) chooseRecent() {
+	locations, err := loadRecent(recentPath())
+	if err != nil {
+		<-a.status.Error(fmt.Sprintf("Failed to load recent locations: %s", err), 2)
+		return
+	}
+	if len(locations) == 0 {
+		<-a.status.Warn("No recent locations yet", 2)
+		return
+	}
+	a.Clear()
+	a.writeHeader()
+	a.status.Message("Choose recent location", 0)
+	index, err := a.selector.Choose(locations.Selectable())
+	a.status.Clear()
+	if err != nil || index < 0 || index >= len(locations) {
+		return
+	}
+	a.openBookmark(locations[index].bookmark())
+}
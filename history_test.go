@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNavHistory(t *testing.T) {
+	h := NewNavHistory()
+	if _, ok := h.back(); ok {
+		t.Errorf("expected empty history can't go back")
+	}
+
+	h.visit(Visit{bucket: ""})
+	h.visit(Visit{bucket: "foo"})
+	h.visit(Visit{bucket: "foo", prefix: []string{"a"}})
+	// Revisiting current location is ignored
+	h.visit(Visit{bucket: "foo", prefix: []string{"a"}})
+
+	assert := func(name string, v Visit, ok bool, expected string) {
+		t.Helper()
+		if !ok || v.key() != expected {
+			t.Errorf("%s: expected %s, actual %s, %t", name, expected, v.key(), ok)
+		}
+	}
+	v, ok := h.back()
+	assert("back", v, ok, "foo/")
+	v, ok = h.back()
+	assert("back to bucket list", v, ok, "/")
+	if _, ok := h.back(); ok {
+		t.Errorf("expected first location can't go back")
+	}
+	v, ok = h.forward()
+	assert("forward", v, ok, "foo/")
+
+	// Visiting new location drops forward history
+	h.visit(Visit{bucket: "foo", prefix: []string{"b", "c"}})
+	if _, ok := h.forward(); ok {
+		t.Errorf("expected forward history is dropped")
+	}
+	v, ok = h.back()
+	assert("back after drop", v, ok, "foo/")
+
+	h.saveView(Visit{bucket: "foo", prefix: []string{"b"}}, ListView{Cursor: 3, Filter: "log"})
+	if view, ok := h.view(Visit{bucket: "foo", prefix: []string{"b"}}); !ok || view.Cursor != 3 || view.Filter != "log" {
+		t.Errorf("expected saved view, actual %v, %t", view, ok)
+	}
+	if _, ok := h.view(Visit{bucket: "foo"}); ok {
+		t.Errorf("expected no view for location which is not saved")
+	}
+}
+
+func TestRecentLocations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ls3-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ls3", "recent.toml")
+	locations, err := loadRecent(path)
+	if err != nil || len(locations) != 0 {
+		t.Fatalf("expected empty locations for missing file, actual %v, %v", locations, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for i := 0; i < maxRecentLocations+5; i++ {
+		locations = locations.add(&RecentLocation{URI: fmt.Sprintf("s3://foo/%d/", i), Visited: now})
+	}
+	// Visiting again moves to first
+	locations = locations.add(&RecentLocation{URI: "s3://foo/34/", Profile: "prod", Visited: now})
+	locations = locations.add(&RecentLocation{URI: "s3://foo/30/", Visited: now})
+	if len(locations) != maxRecentLocations {
+		t.Fatalf("expected %d locations, actual %d", maxRecentLocations, len(locations))
+	}
+	if locations[0].URI != "s3://foo/30/" || locations[1].URI != "s3://foo/34/" || locations[2].URI != "s3://foo/34/" || locations[3].URI != "s3://foo/33/" {
+		t.Errorf("unexpected order %s, %s, %s, %s", locations[0].URI, locations[1].URI, locations[2].URI, locations[3].URI)
+	}
+
+	if err := saveRecent(path, locations); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	loaded, err := loadRecent(path)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if len(loaded) != len(locations) {
+		t.Fatalf("expected %d locations, actual %d", len(locations), len(loaded))
+	}
+	for i, l := range loaded {
+		if l.URI != locations[i].URI || l.Profile != locations[i].Profile || !l.Visited.Equal(locations[i].Visited) {
+			t.Errorf("expected %v, actual %v", locations[i], l)
+		}
+	}
+}
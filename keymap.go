@@ -0,0 +1,437 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Named command which keys are bound to
+type Command string
+
+// Selector commands
+const (
+	CmdDown         Command = "down"
+	CmdUp           Command = "up"
+	CmdPageDown     Command = "page-down"
+	CmdPageUp       Command = "page-up"
+	CmdHalfPageDown Command = "half-page-down"
+	CmdHalfPageUp   Command = "half-page-up"
+	CmdTop          Command = "top"
+	CmdBottom       Command = "bottom"
+	CmdGotoRow      Command = "goto-row"
+	CmdSelect       Command = "select"
+	CmdBack         Command = "back"
+	CmdFilter       Command = "filter"
+	CmdMark         Command = "mark"
+	CmdHelp         Command = "help"
+	CmdErrorDetails Command = "error-details"
+)
+
+// Application commands which finish choosing with KeyBindError
+const (
+	CmdSort           Command = "sort"
+	CmdVersions       Command = "versions"
+	CmdDeleted        Command = "deleted"
+	CmdUndelete       Command = "undelete"
+	CmdActions        Command = "actions"
+	CmdUploadURL      Command = "upload-url"
+	CmdCreateBucket   Command = "create-bucket"
+	CmdGoto           Command = "goto"
+	CmdBookmark       Command = "bookmark"
+	CmdBookmarks      Command = "bookmarks"
+	CmdHistoryBack    Command = "history-back"
+	CmdHistoryForward Command = "history-forward"
+	CmdRecent         Command = "recent"
+)
+
+// Mouse command which finishes choosing when header is clicked, not bound to keys
+const CmdJump Command = "jump"
+
+// All commands in display order
+var commands = []Command{
+	CmdDown, CmdUp, CmdPageDown, CmdPageUp, CmdHalfPageDown, CmdHalfPageUp, CmdTop, CmdBottom, CmdGotoRow, CmdSelect, CmdBack, CmdFilter, CmdMark, CmdHelp, CmdErrorDetails,
+	CmdSort, CmdVersions, CmdDeleted, CmdUndelete, CmdActions, CmdUploadURL, CmdCreateBucket, CmdGoto,
+	CmdBookmark, CmdBookmarks, CmdHistoryBack, CmdHistoryForward, CmdRecent,
+}
+
+// Command descriptions for help
+var commandDescriptions = map[Command]string{
+	CmdDown:           "Move cursor down",
+	CmdUp:             "Move cursor up",
+	CmdPageDown:       "Next page",
+	CmdPageUp:         "Previous page",
+	CmdHalfPageDown:   "Scroll half page down",
+	CmdHalfPageUp:     "Scroll half page up",
+	CmdTop:            "Go to first item",
+	CmdBottom:         "Go to last item",
+	CmdGotoRow:        "Go to row number",
+	CmdSelect:         "Choose item",
+	CmdBack:           "Back or cancel",
+	CmdFilter:         "Start filter input",
+	CmdMark:           "Mark item",
+	CmdHelp:           "Show key bindings",
+	CmdErrorDetails:   "Show last error details",
+	CmdSort:           "Change sort order",
+	CmdVersions:       "Toggle versions",
+	CmdDeleted:        "Toggle deleted objects",
+	CmdUndelete:       "Undelete object",
+	CmdActions:        "Show actions",
+	CmdUploadURL:      "Make upload URL",
+	CmdCreateBucket:   "Create bucket",
+	CmdGoto:           "Go to path",
+	CmdBookmark:       "Bookmark current location",
+	CmdBookmarks:      "Show bookmarks",
+	CmdHistoryBack:    "Back in history",
+	CmdHistoryForward: "Forward in history",
+	CmdRecent:         "Show recent locations",
+}
+
+// Keymap preset
+type keymapPreset struct {
+
+	// Printable keys are input to filter without filter command
+	instantFilter bool
+
+	// Key specs by command
+	bindings map[Command][]string
+}
+
+// Built-in keymap presets
+var keymapPresets = map[string]keymapPreset{
+	"default": {
+		instantFilter: true,
+		bindings: map[Command][]string{
+			CmdDown:           {"down"},
+			CmdUp:             {"up"},
+			CmdPageDown:       {"pgdn"},
+			CmdPageUp:         {"pgup"},
+			CmdHalfPageDown:   {"ctrl+f"},
+			CmdHalfPageUp:     {"ctrl+b"},
+			CmdTop:            {"home"},
+			CmdBottom:         {"end"},
+			CmdGotoRow:        {"ctrl+g"},
+			CmdSelect:         {"enter"},
+			CmdBack:           {"esc", "ctrl+c"},
+			CmdMark:           {"tab"},
+			CmdHelp:           {"f1"},
+			CmdErrorDetails:   {"ctrl+e"},
+			CmdSort:           {"ctrl+s"},
+			CmdVersions:       {"ctrl+v"},
+			CmdDeleted:        {"ctrl+d"},
+			CmdUndelete:       {"ctrl+u"},
+			CmdActions:        {"ctrl+a"},
+			CmdUploadURL:      {"ctrl+p"},
+			CmdCreateBucket:   {"ctrl+n"},
+			CmdGoto:           {"ctrl+l"},
+			CmdBookmark:       {"ctrl+k"},
+			CmdBookmarks:      {"ctrl+o"},
+			CmdHistoryBack:    {"alt+b"},
+			CmdHistoryForward: {"alt+f"},
+			CmdRecent:         {"ctrl+r"},
+		},
+	},
+	"vim": {
+		bindings: map[Command][]string{
+			CmdDown:           {"j", "down"},
+			CmdUp:             {"k", "up"},
+			CmdPageDown:       {"ctrl+f", "pgdn"},
+			CmdPageUp:         {"ctrl+b", "pgup"},
+			CmdHalfPageDown:   {"ctrl+d"},
+			CmdHalfPageUp:     {"ctrl+u"},
+			CmdTop:            {"gg", "home"},
+			CmdBottom:         {"G", "end"},
+			CmdGotoRow:        {":"},
+			CmdSelect:         {"l", "enter"},
+			CmdBack:           {"q", "esc", "ctrl+c"},
+			CmdFilter:         {"/"},
+			CmdMark:           {"space", "tab"},
+			CmdHelp:           {"?"},
+			CmdErrorDetails:   {"E"},
+			CmdSort:           {"s"},
+			CmdVersions:       {"v"},
+			CmdDeleted:        {"D"},
+			CmdUndelete:       {"u"},
+			CmdActions:        {"a"},
+			CmdUploadURL:      {"p"},
+			CmdCreateBucket:   {"n"},
+			CmdGoto:           {"o"},
+			CmdBookmark:       {"m"},
+			CmdBookmarks:      {"'"},
+			CmdHistoryBack:    {"H"},
+			CmdHistoryForward: {"L"},
+			CmdRecent:         {"ctrl+r"},
+		},
+	},
+	"emacs": {
+		instantFilter: true,
+		bindings: map[Command][]string{
+			CmdDown:           {"ctrl+n", "down"},
+			CmdUp:             {"ctrl+p", "up"},
+			CmdPageDown:       {"ctrl+v", "pgdn"},
+			CmdPageUp:         {"alt+v", "pgup"},
+			CmdHalfPageDown:   {"alt+n"},
+			CmdHalfPageUp:     {"alt+p"},
+			CmdTop:            {"alt+<", "home"},
+			CmdBottom:         {"alt+>", "end"},
+			CmdGotoRow:        {"alt+g"},
+			CmdSelect:         {"enter", "ctrl+j"},
+			CmdBack:           {"ctrl+g", "esc", "ctrl+c"},
+			CmdMark:           {"tab"},
+			CmdHelp:           {"f1"},
+			CmdErrorDetails:   {"ctrl+x e"},
+			CmdSort:           {"alt+s"},
+			CmdVersions:       {"ctrl+x v"},
+			CmdDeleted:        {"ctrl+x d"},
+			CmdUndelete:       {"ctrl+x u"},
+			CmdActions:        {"ctrl+x a"},
+			CmdUploadURL:      {"ctrl+x p"},
+			CmdCreateBucket:   {"ctrl+x n"},
+			CmdGoto:           {"ctrl+x ctrl+f"},
+			CmdBookmark:       {"ctrl+x r m"},
+			CmdBookmarks:      {"ctrl+x r b"},
+			CmdHistoryBack:    {"alt+b"},
+			CmdHistoryForward: {"alt+f"},
+			CmdRecent:         {"ctrl+x ctrl+r"},
+		},
+	},
+}
+
+// Named keys for key spec
+var namedKeys = map[string]termbox.Key{
+	"enter":     termbox.KeyEnter,
+	"esc":       termbox.KeyEsc,
+	"tab":       termbox.KeyTab,
+	"backspace": termbox.KeyBackspace,
+	"up":        termbox.KeyArrowUp,
+	"down":      termbox.KeyArrowDown,
+	"left":      termbox.KeyArrowLeft,
+	"right":     termbox.KeyArrowRight,
+	"pgup":      termbox.KeyPgup,
+	"pgdn":      termbox.KeyPgdn,
+	"home":      termbox.KeyHome,
+	"end":       termbox.KeyEnd,
+	"insert":    termbox.KeyInsert,
+	"delete":    termbox.KeyDelete,
+	"f1":        termbox.KeyF1,
+	"f2":        termbox.KeyF2,
+	"f3":        termbox.KeyF3,
+	"f4":        termbox.KeyF4,
+	"f5":        termbox.KeyF5,
+	"f6":        termbox.KeyF6,
+	"f7":        termbox.KeyF7,
+	"f8":        termbox.KeyF8,
+	"f9":        termbox.KeyF9,
+	"f10":       termbox.KeyF10,
+	"f11":       termbox.KeyF11,
+	"f12":       termbox.KeyF12,
+}
+
+// Single key stroke, either special key or character
+type KeyStroke struct {
+
+	// Special key, used when Ch is zero
+	Key termbox.Key
+
+	// Character
+	Ch rune
+
+	// Pressed with Alt
+	Alt bool
+}
+
+// Make key stroke from termbox event
+func strokeOf(evt termbox.Event) KeyStroke {
+	stroke := KeyStroke{Alt: evt.Mod&termbox.ModAlt != 0}
+	switch {
+	case evt.Ch != 0:
+		stroke.Ch = evt.Ch
+	case evt.Key == termbox.KeySpace:
+		stroke.Ch = ' '
+	case evt.Key == termbox.KeyBackspace2:
+		stroke.Key = termbox.KeyBackspace
+	default:
+		stroke.Key = evt.Key
+	}
+	return stroke
+}
+
+// Parse single key token like "j", "ctrl+d", "alt+v", "enter" or "space"
+func parseStroke(token string) (KeyStroke, error) {
+	stroke := KeyStroke{}
+	if strings.HasPrefix(token, "alt+") && len(token) > 4 {
+		stroke.Alt = true
+		token = token[4:]
+	}
+	switch {
+	case token == "space":
+		stroke.Ch = ' '
+	case strings.HasPrefix(token, "ctrl+") && len(token) == 6 && token[5] >= 'a' && token[5] <= 'z':
+		stroke.Key = termbox.Key(token[5]-'a') + termbox.KeyCtrlA
+	case utf8.RuneCountInString(token) == 1:
+		stroke.Ch, _ = utf8.DecodeRuneInString(token)
+	default:
+		key, ok := namedKeys[token]
+		if !ok {
+			return stroke, fmt.Errorf("unknown key %s", token)
+		}
+		stroke.Key = key
+	}
+	return stroke, nil
+}
+
+// Parse key spec into key strokes. Tokens are separated by space, and token of plain characters
+// like "gg" means sequential key strokes
+func parseKeySpec(spec string) ([]KeyStroke, error) {
+	strokes := []KeyStroke{}
+	for _, token := range strings.Fields(spec) {
+		if _, named := namedKeys[token]; !named && !strings.Contains(token, "+") && token != "space" && utf8.RuneCountInString(token) > 1 {
+			for _, r := range token {
+				strokes = append(strokes, KeyStroke{Ch: r})
+			}
+			continue
+		}
+		stroke, err := parseStroke(token)
+		if err != nil {
+			return nil, err
+		}
+		strokes = append(strokes, stroke)
+	}
+	if len(strokes) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return strokes, nil
+}
+
+// Key sequence which is bound to command
+type keySequence struct {
+	strokes []KeyStroke
+	command Command
+}
+
+// Keymap which maps key strokes to named commands
+type Keymap struct {
+
+	// Preset name
+	name string
+
+	// Printable keys are input to filter without filter command
+	instantFilter bool
+
+	// Key specs by command for displaying
+	bindings map[Command][]string
+
+	// Parsed key sequences
+	sequences []keySequence
+}
+
+// Active keymap
+var keymap, _ = NewKeymap("default", nil)
+
+// Create keymap from preset and user overrides. Override replaces all keys of the command
+func NewKeymap(preset string, overrides map[string][]string) (*Keymap, error) {
+	if preset == "" {
+		preset = "default"
+	}
+	p, ok := keymapPresets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown keymap preset %s, available: %s", preset, strings.Join(keymapPresetNames(), ", "))
+	}
+	k := &Keymap{
+		name:          preset,
+		instantFilter: p.instantFilter,
+		bindings:      map[Command][]string{},
+	}
+	for command, specs := range p.bindings {
+		k.bindings[command] = specs
+	}
+
+	known := map[Command]struct{}{}
+	for _, c := range commands {
+		known[c] = struct{}{}
+	}
+	for name, specs := range overrides {
+		if _, ok := known[Command(name)]; !ok {
+			return nil, fmt.Errorf("unknown command %s in keys", name)
+		}
+		k.bindings[Command(name)] = specs
+	}
+
+	for _, command := range commands {
+		for _, spec := range k.bindings[command] {
+			strokes, err := parseKeySpec(spec)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", command, err)
+			}
+			k.sequences = append(k.sequences, keySequence{strokes: strokes, command: command})
+		}
+	}
+	return k, nil
+}
+
+// Resolve pending key strokes. Returns command if matched, and partial flag if strokes are prefix of some sequence
+func (k *Keymap) resolve(pending []KeyStroke, enabled func(Command) bool) (Command, bool) {
+	partial := false
+	for _, seq := range k.sequences {
+		if len(seq.strokes) < len(pending) || !enabled(seq.command) {
+			continue
+		}
+		matched := true
+		for i, stroke := range pending {
+			if seq.strokes[i] != stroke {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if len(seq.strokes) == len(pending) {
+			return seq.command, false
+		}
+		partial = true
+	}
+	return "", partial
+}
+
+// Get key specs of command
+func (k *Keymap) Keys(command Command) []string {
+	return k.bindings[command]
+}
+
+// Make hint text like "tab: mark, ctrl+a: actions" for commands
+func (k *Keymap) Hint(commands ...Command) string {
+	hints := []string{}
+	for _, command := range commands {
+		if keys := k.bindings[command]; len(keys) > 0 {
+			hints = append(hints, fmt.Sprintf("%s: %s", keys[0], command))
+		}
+	}
+	return strings.Join(hints, ", ")
+}
+
+// Make help rows of key specs and description for enabled commands
+func (k *Keymap) Help(enabled func(Command) bool) [][2]string {
+	rows := [][2]string{}
+	for _, command := range commands {
+		keys := k.bindings[command]
+		if len(keys) == 0 || !enabled(command) {
+			continue
+		}
+		rows = append(rows, [2]string{strings.Join(keys, ", "), commandDescriptions[command]})
+	}
+	return rows
+}
+
+// Get preset names
+func keymapPresetNames() []string {
+	names := []string{}
+	for name := range keymapPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestParseKeySpec(t *testing.T) {
+	tests := map[string][]KeyStroke{
+		"j":        {{Ch: 'j'}},
+		"gg":       {{Ch: 'g'}, {Ch: 'g'}},
+		"G":        {{Ch: 'G'}},
+		"ctrl+d":   {{Key: termbox.KeyCtrlD}},
+		"alt+v":    {{Ch: 'v', Alt: true}},
+		"space":    {{Ch: ' '}},
+		"pgdn":     {{Key: termbox.KeyPgdn}},
+		"ctrl+x v": {{Key: termbox.KeyCtrlX}, {Ch: 'v'}},
+		"+":        {{Ch: '+'}},
+	}
+	for spec, expected := range tests {
+		actual, err := parseKeySpec(spec)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %v, actual %v", spec, expected, actual)
+		}
+	}
+	for _, spec := range []string{"", "ctrl+1", "hyper+x"} {
+		if _, err := parseKeySpec(spec); err == nil {
+			t.Errorf("%s: expected error", spec)
+		}
+	}
+}
+
+func TestKeymapResolve(t *testing.T) {
+	k, err := NewKeymap("vim", map[string][]string{"sort": {"S"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	all := func(Command) bool { return true }
+
+	if command, partial := k.resolve([]KeyStroke{{Ch: 'g'}}, all); command != "" || !partial {
+		t.Errorf("expected partial match for g, actual %s, %t", command, partial)
+	}
+	if command, _ := k.resolve([]KeyStroke{{Ch: 'g'}, {Ch: 'g'}}, all); command != CmdTop {
+		t.Errorf("expected top for gg, actual %s", command)
+	}
+	if command, _ := k.resolve([]KeyStroke{{Key: termbox.KeyArrowDown}}, all); command != CmdDown {
+		t.Errorf("expected down, actual %s", command)
+	}
+	if command, _ := k.resolve([]KeyStroke{{Ch: 'S'}}, all); command != CmdSort {
+		t.Errorf("expected overridden sort key, actual %s", command)
+	}
+	if command, _ := k.resolve([]KeyStroke{{Ch: 's'}}, all); command != "" {
+		t.Errorf("expected overridden key is removed, actual %s", command)
+	}
+	none := func(c Command) bool { return c != CmdSort }
+	if command, _ := k.resolve([]KeyStroke{{Ch: 'S'}}, none); command != "" {
+		t.Errorf("expected disabled command is not resolved, actual %s", command)
+	}
+
+	// Instant filter presets don't bind printable help key
+	for _, preset := range []string{"default", "emacs"} {
+		k, err := NewKeymap(preset, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if command, _ := k.resolve([]KeyStroke{{Ch: '?'}}, all); command != "" {
+			t.Errorf("%s: expected ? is typed into filter, actual %s", preset, command)
+		}
+		if command, _ := k.resolve([]KeyStroke{{Key: termbox.KeyF1}}, all); command != CmdHelp {
+			t.Errorf("%s: expected help for f1, actual %s", preset, command)
+		}
+	}
+
+	if _, err := NewKeymap("unknown", nil); err == nil {
+		t.Errorf("unknown preset expected error")
+	}
+	if _, err := NewKeymap("default", map[string][]string{"jump": {"j"}}); err == nil {
+		t.Errorf("unknown command expected error")
+	}
+	if hint := keymap.Hint(CmdMark, CmdActions); hint != "tab: mark, ctrl+a: actions" {
+		t.Errorf("unexpected hint %s", hint)
+	}
+}
+
+func TestKeymapHelp(t *testing.T) {
+	k, err := NewKeymap("vim", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	enabled := func(c Command) bool {
+		return c == CmdDown || c == CmdHelp || c == CmdSort
+	}
+	expected := [][2]string{
+		{"j, down", "Move cursor down"},
+		{"?", "Show key bindings"},
+		{"s", "Change sort order"},
+	}
+	if actual := k.Help(enabled); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, actual %v", expected, actual)
+	}
+	for _, c := range commands {
+		if _, ok := commandDescriptions[c]; !ok {
+			t.Errorf("description of %s is not defined", c)
+		}
+	}
+}
@@ -0,0 +1,676 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// Lifecycle rule form labels
+const (
+	ruleID                     = "ID"
+	ruleStatus                 = "Status"
+	rulePrefix                 = "Filter Prefix"
+	ruleTags                   = "Filter Tags"
+	ruleMinSize                = "Min Size (bytes)"
+	ruleMaxSize                = "Max Size (bytes)"
+	ruleTransitions            = "Transitions"
+	ruleExpiration             = "Expiration"
+	ruleExpiredDeleteMarker    = "Expired Delete Marker"
+	ruleNoncurrentTransitions  = "Noncurrent Transitions"
+	ruleNoncurrentExpiration   = "Noncurrent Expiration"
+	ruleNoncurrentKeepVersions = "Noncurrent Keep Versions"
+	ruleAbortMultipart         = "Abort Multipart Days"
+)
+
+// Date format for date based transition and expiration
+const ruleDateFormat = "2006-01-02"
+
+// Storage classes which lifecycle rule can transition to
+var lifecycleStorageClasses = map[string]struct{}{
+	s3.TransitionStorageClassStandardIa:         {},
+	s3.TransitionStorageClassOnezoneIa:          {},
+	s3.TransitionStorageClassIntelligentTiering: {},
+	s3.TransitionStorageClassGlacierIr:          {},
+	s3.TransitionStorageClassGlacier:            {},
+	s3.TransitionStorageClassDeepArchive:        {},
+}
+
+// Lifecycle rule struct for selector
+type LifecycleRule struct {
+
+	// S3 lifecycle rule
+	rule *s3.LifecycleRule
+
+	Writer
+}
+
+// Writer::String implementation
+func (l *LifecycleRule) String() string {
+	return fmt.Sprintf("[%s] %s %s", aws.StringValue(l.rule.Status), aws.StringValue(l.rule.ID), l.summary())
+}
+
+// Writer::Write implementation
+func (l *LifecycleRule) Write(y int, filter string) {
+	i := 0
+	style := theme.Success
+	if aws.StringValue(l.rule.Status) != s3.ExpirationStatusEnabled {
+		style = theme.Danger
+	}
+	for _, r := range []rune(fmt.Sprintf("[%-8s] ", aws.StringValue(l.rule.Status))) {
+		termbox.SetCell(i, y, r, style.Fg, style.Bg)
+		i++
+	}
+	for _, r := range []rune(fmt.Sprintf("%-24s ", aws.StringValue(l.rule.ID))) {
+		termbox.SetCell(i, y, r, theme.Title.Fg, theme.Title.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+	for _, r := range []rune(l.summary()) {
+		termbox.SetCell(i, y, r, theme.Label.Fg, theme.Label.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+}
+
+// Get one line summary of rule
+func (l *LifecycleRule) summary() string {
+	values := ruleValues(l.rule)
+	parts := []string{}
+	for _, label := range []string{rulePrefix, ruleTags, ruleTransitions, ruleExpiration, ruleNoncurrentTransitions, ruleNoncurrentExpiration} {
+		if values[label] != "" {
+			parts = append(parts, fmt.Sprintf("%s: %s", label, values[label]))
+		}
+	}
+	if values[ruleExpiredDeleteMarker] == "Yes" {
+		parts = append(parts, "Remove expired delete markers")
+	}
+	if values[ruleAbortMultipart] != "" {
+		parts = append(parts, fmt.Sprintf("Abort multipart: %s days", values[ruleAbortMultipart]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Format days or date
+func daysOrDate(days *int64, date *time.Time) string {
+	if date != nil {
+		return date.Format(ruleDateFormat)
+	}
+	if days != nil {
+		return fmt.Sprint(*days)
+	}
+	return ""
+}
+
+// Format tags as "key=value,key=value"
+func formatTagFilter(tags []*s3.Tag) string {
+	pairs := []string{}
+	for _, t := range tags {
+		pairs = append(pairs, aws.StringValue(t.Key)+"="+aws.StringValue(t.Value))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Format optional int64
+func formatInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(*v)
+}
+
+// Transform lifecycle rule into form values
+func ruleValues(rule *s3.LifecycleRule) map[string]string {
+	values := map[string]string{
+		ruleID:                  aws.StringValue(rule.ID),
+		ruleStatus:              aws.StringValue(rule.Status),
+		rulePrefix:              aws.StringValue(rule.Prefix),
+		ruleExpiredDeleteMarker: "No",
+	}
+	if f := rule.Filter; f != nil {
+		switch {
+		case f.And != nil:
+			values[rulePrefix] = aws.StringValue(f.And.Prefix)
+			values[ruleTags] = formatTagFilter(f.And.Tags)
+			values[ruleMinSize] = formatInt(f.And.ObjectSizeGreaterThan)
+			values[ruleMaxSize] = formatInt(f.And.ObjectSizeLessThan)
+		case f.Tag != nil:
+			values[ruleTags] = formatTagFilter([]*s3.Tag{f.Tag})
+		default:
+			if f.Prefix != nil {
+				values[rulePrefix] = *f.Prefix
+			}
+			values[ruleMinSize] = formatInt(f.ObjectSizeGreaterThan)
+			values[ruleMaxSize] = formatInt(f.ObjectSizeLessThan)
+		}
+	}
+
+	transitions := []string{}
+	for _, t := range rule.Transitions {
+		transitions = append(transitions, daysOrDate(t.Days, t.Date)+":"+aws.StringValue(t.StorageClass))
+	}
+	values[ruleTransitions] = strings.Join(transitions, ",")
+	if e := rule.Expiration; e != nil {
+		values[ruleExpiration] = daysOrDate(e.Days, e.Date)
+		if aws.BoolValue(e.ExpiredObjectDeleteMarker) {
+			values[ruleExpiredDeleteMarker] = "Yes"
+		}
+	}
+
+	noncurrent := []string{}
+	for _, t := range rule.NoncurrentVersionTransitions {
+		noncurrent = append(noncurrent, formatInt(t.NoncurrentDays)+":"+aws.StringValue(t.StorageClass))
+	}
+	values[ruleNoncurrentTransitions] = strings.Join(noncurrent, ",")
+	if e := rule.NoncurrentVersionExpiration; e != nil {
+		values[ruleNoncurrentExpiration] = formatInt(e.NoncurrentDays)
+		values[ruleNoncurrentKeepVersions] = formatInt(e.NewerNoncurrentVersions)
+	}
+	if a := rule.AbortIncompleteMultipartUpload; a != nil {
+		values[ruleAbortMultipart] = formatInt(a.DaysAfterInitiation)
+	}
+	return values
+}
+
+// Parse optional positive number
+func parsePositive(label, value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("%s must be a positive number", label)
+	}
+	return aws.Int64(n), nil
+}
+
+// Parse days or date in "2006-01-02" format
+func parseDaysOrDate(label, value string) (*int64, *time.Time, error) {
+	if date, err := time.Parse(ruleDateFormat, value); err == nil {
+		return nil, aws.Time(date), nil
+	}
+	days, err := parsePositive(label, value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s must be days or date (YYYY-MM-DD)", label)
+	}
+	return days, nil, nil
+}
+
+// Split "days:STORAGE_CLASS" list into pairs
+func splitTransitions(label, value string) ([][2]string, error) {
+	pairs := [][2]string{}
+	if value == "" {
+		return pairs, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s must be formatted as days:STORAGE_CLASS", label)
+		}
+		storageClass := strings.ToUpper(strings.TrimSpace(parts[1]))
+		if _, ok := lifecycleStorageClasses[storageClass]; !ok {
+			return nil, fmt.Errorf("%s has unknown storage class %s", label, storageClass)
+		}
+		pairs = append(pairs, [2]string{strings.TrimSpace(parts[0]), storageClass})
+	}
+	return pairs, nil
+}
+
+// Parse current version transitions, days or date are allowed
+func parseTransitions(value string) ([]*s3.Transition, error) {
+	pairs, err := splitTransitions(ruleTransitions, value)
+	if err != nil {
+		return nil, err
+	}
+	transitions := []*s3.Transition{}
+	for _, pair := range pairs {
+		days, date, err := parseDaysOrDate(ruleTransitions, pair[0])
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, &s3.Transition{
+			Days:         days,
+			Date:         date,
+			StorageClass: aws.String(pair[1]),
+		})
+	}
+	return transitions, nil
+}
+
+// Parse noncurrent version transitions, only days are allowed
+func parseNoncurrentTransitions(value string) ([]*s3.NoncurrentVersionTransition, error) {
+	pairs, err := splitTransitions(ruleNoncurrentTransitions, value)
+	if err != nil {
+		return nil, err
+	}
+	transitions := []*s3.NoncurrentVersionTransition{}
+	for _, pair := range pairs {
+		days, err := parsePositive(ruleNoncurrentTransitions, pair[0])
+		if err != nil || days == nil {
+			return nil, fmt.Errorf("%s must be formatted as days:STORAGE_CLASS", ruleNoncurrentTransitions)
+		}
+		transitions = append(transitions, &s3.NoncurrentVersionTransition{
+			NoncurrentDays: days,
+			StorageClass:   aws.String(pair[1]),
+		})
+	}
+	return transitions, nil
+}
+
+// Parse "key=value,key=value" tag filter
+func parseTagFilter(value string) ([]*s3.Tag, error) {
+	tags := []*s3.Tag{}
+	if value == "" {
+		return tags, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%s must be formatted as key=value,key=value", ruleTags)
+		}
+		tags = append(tags, &s3.Tag{Key: aws.String(parts[0]), Value: aws.String(parts[1])})
+	}
+	return tags, nil
+}
+
+// Build lifecycle rule from original rule and form values. Fields which the form doesn't edit are kept
+func parseRule(original *s3.LifecycleRule, values map[string]string) (*s3.LifecycleRule, error) {
+	rule := &s3.LifecycleRule{}
+	if original != nil {
+		copied := *original
+		rule = &copied
+	}
+	rule.ID = aws.String(values[ruleID])
+	rule.Status = aws.String(values[ruleStatus])
+	// Deprecated prefix is replaced by filter
+	rule.Prefix = nil
+
+	tags, err := parseTagFilter(values[ruleTags])
+	if err != nil {
+		return nil, err
+	}
+	minSize, err := parsePositive(ruleMinSize, values[ruleMinSize])
+	if err != nil {
+		return nil, err
+	}
+	maxSize, err := parsePositive(ruleMaxSize, values[ruleMaxSize])
+	if err != nil {
+		return nil, err
+	}
+	conditions := len(tags)
+	if values[rulePrefix] != "" {
+		conditions++
+	}
+	if minSize != nil {
+		conditions++
+	}
+	if maxSize != nil {
+		conditions++
+	}
+	switch {
+	case conditions > 1:
+		and := &s3.LifecycleRuleAndOperator{
+			ObjectSizeGreaterThan: minSize,
+			ObjectSizeLessThan:    maxSize,
+		}
+		if values[rulePrefix] != "" {
+			and.Prefix = aws.String(values[rulePrefix])
+		}
+		if len(tags) > 0 {
+			and.Tags = tags
+		}
+		rule.Filter = &s3.LifecycleRuleFilter{And: and}
+	case len(tags) == 1:
+		rule.Filter = &s3.LifecycleRuleFilter{Tag: tags[0]}
+	default:
+		rule.Filter = &s3.LifecycleRuleFilter{
+			Prefix:                aws.String(values[rulePrefix]),
+			ObjectSizeGreaterThan: minSize,
+			ObjectSizeLessThan:    maxSize,
+		}
+	}
+
+	transitions, err := parseTransitions(values[ruleTransitions])
+	if err != nil {
+		return nil, err
+	}
+	rule.Transitions = nil
+	if len(transitions) > 0 {
+		rule.Transitions = transitions
+	}
+	rule.Expiration = nil
+	if values[ruleExpiration] != "" || values[ruleExpiredDeleteMarker] == "Yes" {
+		rule.Expiration = &s3.LifecycleExpiration{}
+		if values[ruleExpiration] != "" {
+			days, date, err := parseDaysOrDate(ruleExpiration, values[ruleExpiration])
+			if err != nil {
+				return nil, err
+			}
+			rule.Expiration.Days = days
+			rule.Expiration.Date = date
+		}
+		if values[ruleExpiredDeleteMarker] == "Yes" {
+			rule.Expiration.ExpiredObjectDeleteMarker = aws.Bool(true)
+		}
+	}
+
+	noncurrent, err := parseNoncurrentTransitions(values[ruleNoncurrentTransitions])
+	if err != nil {
+		return nil, err
+	}
+	// Newer noncurrent versions of transition is not edited by form, so keep it of the same transition
+	if original != nil {
+		for _, t := range noncurrent {
+			for _, o := range original.NoncurrentVersionTransitions {
+				if aws.Int64Value(o.NoncurrentDays) == aws.Int64Value(t.NoncurrentDays) && aws.StringValue(o.StorageClass) == aws.StringValue(t.StorageClass) {
+					t.NewerNoncurrentVersions = o.NewerNoncurrentVersions
+				}
+			}
+		}
+	}
+	rule.NoncurrentVersionTransitions = nil
+	if len(noncurrent) > 0 {
+		rule.NoncurrentVersionTransitions = noncurrent
+	}
+	noncurrentDays, err := parsePositive(ruleNoncurrentExpiration, values[ruleNoncurrentExpiration])
+	if err != nil {
+		return nil, err
+	}
+	keepVersions, err := parsePositive(ruleNoncurrentKeepVersions, values[ruleNoncurrentKeepVersions])
+	if err != nil {
+		return nil, err
+	}
+	rule.NoncurrentVersionExpiration = nil
+	if noncurrentDays != nil {
+		rule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{
+			NoncurrentDays:          noncurrentDays,
+			NewerNoncurrentVersions: keepVersions,
+		}
+	} else if keepVersions != nil {
+		return nil, fmt.Errorf("%s requires %s", ruleNoncurrentKeepVersions, ruleNoncurrentExpiration)
+	}
+
+	abortDays, err := parsePositive(ruleAbortMultipart, values[ruleAbortMultipart])
+	if err != nil {
+		return nil, err
+	}
+	rule.AbortIncompleteMultipartUpload = nil
+	if abortDays != nil {
+		rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{DaysAfterInitiation: abortDays}
+	}
+	return rule, nil
+}
+
+// Validate a lifecycle rule
+func validateRule(rule *s3.LifecycleRule) error {
+	id := aws.StringValue(rule.ID)
+	if id == "" || len(id) > 255 {
+		return fmt.Errorf("ID must be 1-255 characters")
+	}
+	status := aws.StringValue(rule.Status)
+	if status != s3.ExpirationStatusEnabled && status != s3.ExpirationStatusDisabled {
+		return fmt.Errorf("%s: Status must be Enabled or Disabled", id)
+	}
+	if len(rule.Transitions) == 0 && rule.Expiration == nil && len(rule.NoncurrentVersionTransitions) == 0 &&
+		rule.NoncurrentVersionExpiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+		return fmt.Errorf("%s: rule must have at least one action", id)
+	}
+
+	hasTags := rule.Filter != nil && (rule.Filter.Tag != nil || (rule.Filter.And != nil && len(rule.Filter.And.Tags) > 0))
+	if e := rule.Expiration; e != nil && aws.BoolValue(e.ExpiredObjectDeleteMarker) {
+		if e.Days != nil || e.Date != nil {
+			return fmt.Errorf("%s: expired delete marker cannot be combined with expiration days or date", id)
+		}
+		if hasTags {
+			return fmt.Errorf("%s: expired delete marker cannot be used with tag filter", id)
+		}
+	}
+	if rule.AbortIncompleteMultipartUpload != nil && hasTags {
+		return fmt.Errorf("%s: abort incomplete multipart upload cannot be used with tag filter", id)
+	}
+
+	// Transitions must be in ascending order and before expiration
+	var last int64 = -1
+	for _, t := range rule.Transitions {
+		if t.Days == nil {
+			continue
+		}
+		days := *t.Days
+		storageClass := aws.StringValue(t.StorageClass)
+		if (storageClass == s3.TransitionStorageClassStandardIa || storageClass == s3.TransitionStorageClassOnezoneIa) && days < 30 {
+			return fmt.Errorf("%s: transition to %s requires at least 30 days", id, storageClass)
+		}
+		if days <= last {
+			return fmt.Errorf("%s: transition days must be in ascending order", id)
+		}
+		last = days
+	}
+	if e := rule.Expiration; e != nil && e.Days != nil && *e.Days <= last {
+		return fmt.Errorf("%s: expiration days must be greater than transition days", id)
+	}
+	return nil
+}
+
+// Validate all rules, IDs must be unique
+func validateRules(rules []*s3.LifecycleRule) error {
+	if len(rules) > 1000 {
+		return fmt.Errorf("Lifecycle configuration can have 1000 rules at most")
+	}
+	ids := map[string]struct{}{}
+	for _, rule := range rules {
+		if err := validateRule(rule); err != nil {
+			return err
+		}
+		if _, exists := ids[*rule.ID]; exists {
+			return fmt.Errorf("%s: rule ID must be unique", *rule.ID)
+		}
+		ids[*rule.ID] = struct{}{}
+	}
+	return nil
+}
+
+// Lifecycle rule editor for bucket
+type LifecycleEditor struct {
+
+	// S3 service instance for bucket region
+	service *s3.S3
+
+	// Target bucket
+	bucket *Bucket
+
+	// Current rules
+	rules []*s3.LifecycleRule
+
+	// Status Writer
+	status *Status
+
+	// Injected Selector
+	selector *Selector
+
+	// row offset for termbox
+	offset int
+}
+
+// Create LifecycleEditor pointer
+func NewLifecycleEditor(service *s3.S3, bucket *Bucket, selector *Selector, status *Status, offset int) *LifecycleEditor {
+	return &LifecycleEditor{
+		service:  service,
+		bucket:   bucket,
+		selector: selector,
+		status:   status,
+		offset:   offset,
+	}
+}
+
+// Display rules and edit them
+func (l *LifecycleEditor) Do() error {
+	l.status.Message("Retriving bucket region...", 0)
+	region, err := s3manager.GetBucketRegionWithClient(aws.BackgroundContext(), l.service, l.bucket.name)
+	if err != nil {
+		<-l.status.Failure("Failed to get bucket region", err, 2)
+		return nil
+	}
+	l.service = regionalService(l.service, region)
+
+	l.status.Message("Retriving lifecycle rules...", 0)
+	result, err := l.service.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(l.bucket.name),
+	})
+	switch {
+	case errorCode(err) == "NoSuchLifecycleConfiguration":
+		l.rules = []*s3.LifecycleRule{}
+	case err != nil:
+		<-l.status.Failure("Failed to get lifecycle rules", err, 2)
+		return nil
+	default:
+		l.rules = result.Rules
+	}
+
+	for {
+		list := Selectable{ActionCommand{op: AddField, name: "[+] Add rule"}}
+		for _, rule := range l.rules {
+			list = append(list, &LifecycleRule{rule: rule})
+		}
+
+		l.selector.SetOffset(l.offset).WithOutFilter()
+		l.status.Message(fmt.Sprintf("Lifecycle rules of %s (Enter: edit, Esc: back)", l.bucket.name), 0)
+		index, err := l.selector.Choose(list)
+		l.selector.WithFilter()
+		if err != nil || index < 0 {
+			return nil
+		}
+
+		if index == 0 {
+			rule := &s3.LifecycleRule{
+				ID:     aws.String(fmt.Sprintf("rule-%d", len(l.rules)+1)),
+				Status: aws.String(s3.ExpirationStatusEnabled),
+			}
+			if updated, ok := l.editRule(rule); ok {
+				l.save(append(append([]*s3.LifecycleRule{}, l.rules...), updated))
+			}
+			continue
+		}
+		l.ruleAction(index - 1)
+	}
+}
+
+// Choose action for rule
+func (l *LifecycleEditor) ruleAction(index int) {
+	rule := l.rules[index]
+	toggle := "Disable this rule"
+	if aws.StringValue(rule.Status) != s3.ExpirationStatusEnabled {
+		toggle = "Enable this rule"
+	}
+	actions := ActionList{
+		ActionCommand{op: Back, name: "Back To Rules"},
+		ActionCommand{op: EditRule, name: "Edit this rule"},
+		ActionCommand{op: ToggleRule, name: toggle},
+		ActionCommand{op: DeleteRule, name: "Delete this rule"},
+	}
+	l.selector.SetOffset(l.offset).WithOutFilter()
+	l.status.Message(fmt.Sprintf("Choose action for rule %s", aws.StringValue(rule.ID)), 0)
+	chosen, err := l.selector.Choose(actions.Selectable())
+	l.selector.WithFilter()
+	if err != nil || chosen < 0 {
+		return
+	}
+
+	rules := append([]*s3.LifecycleRule{}, l.rules...)
+	switch actions[chosen].op {
+	case EditRule:
+		updated, ok := l.editRule(rule)
+		if !ok {
+			return
+		}
+		rules[index] = updated
+	case ToggleRule:
+		updated := *rule
+		if aws.StringValue(rule.Status) == s3.ExpirationStatusEnabled {
+			updated.Status = aws.String(s3.ExpirationStatusDisabled)
+		} else {
+			updated.Status = aws.String(s3.ExpirationStatusEnabled)
+		}
+		rules[index] = &updated
+	case DeleteRule:
+		if !l.selector.Confirm(fmt.Sprintf("Delete lifecycle rule %s?", aws.StringValue(rule.ID))) {
+			return
+		}
+		rules = append(rules[0:index], rules[index+1:]...)
+	default:
+		return
+	}
+	l.save(rules)
+}
+
+// Edit rule by form until it is valid or canceled
+func (l *LifecycleEditor) editRule(rule *s3.LifecycleRule) (*s3.LifecycleRule, bool) {
+	values := ruleValues(rule)
+	form := NewForm("Edit lifecycle rule (transitions: days:CLASS,... / expiration: days or YYYY-MM-DD)", l.selector.SetOffset(l.offset), l.status)
+	form.Field(ruleID, values[ruleID])
+	form.Field(ruleStatus, values[ruleStatus], s3.ExpirationStatusEnabled, s3.ExpirationStatusDisabled)
+	form.Field(rulePrefix, values[rulePrefix])
+	form.Field(ruleTags, values[ruleTags])
+	form.Field(ruleMinSize, values[ruleMinSize])
+	form.Field(ruleMaxSize, values[ruleMaxSize])
+	form.Field(ruleTransitions, values[ruleTransitions])
+	form.Field(ruleExpiration, values[ruleExpiration])
+	form.Field(ruleExpiredDeleteMarker, values[ruleExpiredDeleteMarker], "No", "Yes")
+	form.Field(ruleNoncurrentTransitions, values[ruleNoncurrentTransitions])
+	form.Field(ruleNoncurrentExpiration, values[ruleNoncurrentExpiration])
+	form.Field(ruleNoncurrentKeepVersions, values[ruleNoncurrentKeepVersions])
+	form.Field(ruleAbortMultipart, values[ruleAbortMultipart])
+
+	for {
+		saved, err := form.Do()
+		if err != nil || !saved {
+			return nil, false
+		}
+		edited := map[string]string{}
+		for _, field := range form.fields {
+			edited[field.label] = field.value
+		}
+		updated, err := parseRule(rule, edited)
+		if err == nil {
+			err = validateRule(updated)
+		}
+		if err != nil {
+			<-l.status.Error(err.Error(), 2)
+			continue
+		}
+		return updated, true
+	}
+}
+
+// Validate and put rules, or delete configuration if rules are empty
+func (l *LifecycleEditor) save(rules []*s3.LifecycleRule) {
+	if err := validateRules(rules); err != nil {
+		<-l.status.Error(err.Error(), 2)
+		return
+	}
+
+	l.status.Info("Saving lifecycle rules...", 0)
+	var err error
+	if len(rules) == 0 {
+		_, err = l.service.DeleteBucketLifecycle(&s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(l.bucket.name),
+		})
+	} else {
+		_, err = l.service.PutBucketLifecycleConfiguration(&s3.PutBucketLifecycleConfigurationInput{
+			Bucket: aws.String(l.bucket.name),
+			LifecycleConfiguration: &s3.BucketLifecycleConfiguration{
+				Rules: rules,
+			},
+		})
+	}
+	if err != nil {
+		<-l.status.Failure("Failed to save lifecycle rules", err, 2)
+		return
+	}
+	l.rules = rules
+	<-l.status.Info("Saved lifecycle rules", 1)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestParseRule(t *testing.T) {
+	values := map[string]string{
+		ruleID:                    "archive",
+		ruleStatus:                s3.ExpirationStatusEnabled,
+		rulePrefix:                "logs/",
+		ruleTags:                  "env=prod",
+		ruleTransitions:           "30:standard_ia,90:GLACIER",
+		ruleExpiration:            "365",
+		ruleExpiredDeleteMarker:   "No",
+		ruleNoncurrentTransitions: "30:GLACIER",
+		ruleNoncurrentExpiration:  "60",
+	}
+	rule, err := parseRule(nil, values)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rule.Filter.And == nil || aws.StringValue(rule.Filter.And.Prefix) != "logs/" || len(rule.Filter.And.Tags) != 1 {
+		t.Errorf("expected And filter with prefix and tag, actual %v", rule.Filter)
+	}
+	if len(rule.Transitions) != 2 || aws.StringValue(rule.Transitions[0].StorageClass) != s3.TransitionStorageClassStandardIa {
+		t.Errorf("expected 2 transitions, actual %v", rule.Transitions)
+	}
+	if aws.Int64Value(rule.Expiration.Days) != 365 {
+		t.Errorf("expected expiration 365 days, actual %v", rule.Expiration)
+	}
+	if err := validateRule(rule); err != nil {
+		t.Errorf("expected valid rule, actual %s", err)
+	}
+
+	// form values should be restored from rule
+	restored := ruleValues(rule)
+	for _, label := range []string{ruleID, rulePrefix, ruleTags, ruleExpiration, ruleNoncurrentTransitions, ruleNoncurrentExpiration} {
+		if restored[label] != values[label] {
+			t.Errorf("%s expected %s, actual %s", label, values[label], restored[label])
+		}
+	}
+	if restored[ruleTransitions] != "30:STANDARD_IA,90:GLACIER" {
+		t.Errorf("unexpected transitions %s", restored[ruleTransitions])
+	}
+
+	single, err := parseRule(nil, map[string]string{ruleID: "tag", ruleStatus: "Enabled", ruleTags: "a=b", ruleExpiration: "2030-01-01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if single.Filter.Tag == nil || single.Expiration.Date == nil {
+		t.Errorf("expected tag filter and date expiration, actual %v", single)
+	}
+
+	for _, v := range []map[string]string{
+		{ruleTransitions: "30"},
+		{ruleTransitions: "30:UNKNOWN"},
+		{ruleNoncurrentTransitions: "2030-01-01:GLACIER"},
+		{ruleTags: "novalue"},
+		{ruleExpiration: "tomorrow"},
+		{ruleExpiration: "0"},
+		{ruleTransitions: "0:GLACIER"},
+		{ruleAbortMultipart: "-1"},
+		{ruleNoncurrentKeepVersions: "3"},
+	} {
+		if _, err := parseRule(nil, v); err == nil {
+			t.Errorf("expected parse error for %v", v)
+		}
+	}
+}
+
+func TestParseRuleKeepsOriginal(t *testing.T) {
+	original := &s3.LifecycleRule{
+		ID:     aws.String("noncurrent"),
+		Status: aws.String(s3.ExpirationStatusEnabled),
+		Filter: &s3.LifecycleRuleFilter{And: &s3.LifecycleRuleAndOperator{
+			Prefix:                aws.String("logs/"),
+			ObjectSizeGreaterThan: aws.Int64(1024),
+			ObjectSizeLessThan:    aws.Int64(4096),
+		}},
+		NoncurrentVersionTransitions: []*s3.NoncurrentVersionTransition{{
+			NoncurrentDays:          aws.Int64(30),
+			NewerNoncurrentVersions: aws.Int64(2),
+			StorageClass:            aws.String(s3.TransitionStorageClassGlacier),
+		}},
+		NoncurrentVersionExpiration: &s3.NoncurrentVersionExpiration{
+			NoncurrentDays:          aws.Int64(90),
+			NewerNoncurrentVersions: aws.Int64(3),
+		},
+		AbortIncompleteMultipartUpload: &s3.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int64(7)},
+	}
+
+	// Unchanged form makes the same rule
+	rule, err := parseRule(original, ruleValues(original))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(rule, original) {
+		t.Errorf("expected %v, actual %v", original, rule)
+	}
+
+	values := ruleValues(original)
+	values[ruleStatus] = s3.ExpirationStatusDisabled
+	values[ruleAbortMultipart] = ""
+	rule, err = parseRule(original, values)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if aws.StringValue(rule.Status) != s3.ExpirationStatusDisabled || rule.AbortIncompleteMultipartUpload != nil {
+		t.Errorf("edited fields must be updated, actual %v", rule)
+	}
+	if aws.Int64Value(rule.NoncurrentVersionTransitions[0].NewerNoncurrentVersions) != 2 {
+		t.Errorf("newer noncurrent versions of transition must be kept, actual %v", rule.NoncurrentVersionTransitions)
+	}
+	if aws.StringValue(original.Status) != s3.ExpirationStatusEnabled {
+		t.Errorf("original rule must not be modified")
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	rule := func(id string, f func(r *s3.LifecycleRule)) *s3.LifecycleRule {
+		r := &s3.LifecycleRule{
+			ID:         aws.String(id),
+			Status:     aws.String(s3.ExpirationStatusEnabled),
+			Filter:     &s3.LifecycleRuleFilter{Prefix: aws.String("")},
+			Expiration: &s3.LifecycleExpiration{Days: aws.Int64(365)},
+		}
+		f(r)
+		return r
+	}
+	noop := func(r *s3.LifecycleRule) {}
+
+	if err := validateRules([]*s3.LifecycleRule{rule("a", noop), rule("b", noop)}); err != nil {
+		t.Errorf("expected valid, actual %s", err)
+	}
+	invalid := map[string][]*s3.LifecycleRule{
+		"duplicated id": {rule("a", noop), rule("a", noop)},
+		"empty id":      {rule("", noop)},
+		"no action": {rule("a", func(r *s3.LifecycleRule) {
+			r.Expiration = nil
+		})},
+		"IA before 30 days": {rule("a", func(r *s3.LifecycleRule) {
+			r.Transitions = []*s3.Transition{{Days: aws.Int64(10), StorageClass: aws.String(s3.TransitionStorageClassStandardIa)}}
+		})},
+		"descending transitions": {rule("a", func(r *s3.LifecycleRule) {
+			r.Transitions = []*s3.Transition{
+				{Days: aws.Int64(90), StorageClass: aws.String(s3.TransitionStorageClassGlacier)},
+				{Days: aws.Int64(60), StorageClass: aws.String(s3.TransitionStorageClassStandardIa)},
+			}
+		})},
+		"expiration before transition": {rule("a", func(r *s3.LifecycleRule) {
+			r.Transitions = []*s3.Transition{{Days: aws.Int64(400), StorageClass: aws.String(s3.TransitionStorageClassGlacier)}}
+		})},
+		"delete marker with days": {rule("a", func(r *s3.LifecycleRule) {
+			r.Expiration.ExpiredObjectDeleteMarker = aws.Bool(true)
+		})},
+		"delete marker with tags": {rule("a", func(r *s3.LifecycleRule) {
+			r.Filter = &s3.LifecycleRuleFilter{Tag: &s3.Tag{Key: aws.String("k"), Value: aws.String("v")}}
+			r.Expiration = &s3.LifecycleExpiration{ExpiredObjectDeleteMarker: aws.Bool(true)}
+		})},
+	}
+	for name, rules := range invalid {
+		if err := validateRules(rules); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
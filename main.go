@@ -9,7 +9,6 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
@@ -24,6 +23,15 @@ type CLI struct {
 	// Determine region
 	region string
 
+	// S3 compatible endpoint URL
+	endpoint string
+
+	// Configuration file path
+	config string
+
+	// Bookmark name to open at startup
+	bookmark string
+
 	// Using profile from environment
 	env bool
 
@@ -33,19 +41,16 @@ type CLI struct {
 
 var cli CLI = CLI{}
 
-// init() for parsing command line args
+// init() for defining command line args
 func init() {
-	flag.StringVar(&cli.bucket, "bucket", "", "Using bucket name")
+	flag.StringVar(&cli.bucket, "bucket", "", "Using bucket name or s3://bucket/prefix/")
 	flag.StringVar(&cli.profile, "profile", "", "Use profile name")
 	flag.BoolVar(&cli.env, "env", false, "Use credentials from environment")
-	flag.StringVar(&cli.region, "region", "ap-northeast-1", "region name")
+	flag.StringVar(&cli.region, "region", "", "region name")
+	flag.StringVar(&cli.endpoint, "endpoint", "", "S3 compatible endpoint URL")
+	flag.StringVar(&cli.config, "config", "", "configuration file path")
+	flag.StringVar(&cli.bookmark, "bookmark", "", "bookmark name to open")
 	flag.BoolVar(&cli.help, "help", false, "show usage")
-	flag.Parse()
-
-	if cli.help {
-		showUsage()
-		os.Exit(0)
-	}
 }
 
 // show usage
@@ -61,9 +66,15 @@ Options:
                             If not supplied, use default profile
   -env                    : Use credentials from environment variable
                             You need to export AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
-  -bucket                 : Initial bucket name
+  -bucket [name or uri]   : Initial bucket name, or URI with prefix like s3://bucket/path/to/
   -region [region name]   : Determine region (default: ap-northeast-1)
+  -endpoint [url]         : Use S3 compatible endpoint URL
+  -config [path]          : Configuration file path
+                            (default: $XDG_CONFIG_HOME/ls3/config.toml)
+  -bookmark [name]        : Open bookmarked location
   -help                   : Show this help
+
+Command line options take precedence over the configuration file.
 `
 	fmt.Println(help)
 }
@@ -86,18 +97,105 @@ func configFromEnv(region string) *aws.Config {
 		WithRegion(region)
 }
 
-// Main function
-func main() {
-	defer logger.Close()
+// Create S3 service for profile, region and endpoint
+func newService(profile, region, endpoint string) *s3.S3 {
 	var conf *aws.Config
 	if cli.env {
-		conf = configFromEnv(cli.region)
+		conf = configFromEnv(region)
 	} else {
-		conf = configFromProfile(cli.profile, cli.region)
+		conf = configFromProfile(profile, region)
 	}
+	if endpoint != "" {
+		conf = conf.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	return newClient(conf)
+}
 
-	service := s3.New(session.Must(session.NewSession()), conf)
-	app, err := NewApp(service, cli.bucket)
+// Get endpoint for profile. Startup profile uses configured endpoint which -endpoint option overrides,
+// and other profile uses endpoint of its profile section if configured
+func profileEndpoint(profile string) string {
+	if p, ok := config.Profiles[profile]; ok && profile != config.Profile && p.Endpoint != "" {
+		return p.Endpoint
+	}
+	return config.Endpoint
+}
+
+// Find bookmark by name for -bookmark option
+func findBookmark(name string) (*Bookmark, error) {
+	path := bookmarksPath()
+	bookmarks, err := loadBookmarks(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load %s: %s", path, err)
+	}
+	bookmark := bookmarks.find(name)
+	if bookmark == nil {
+		return nil, fmt.Errorf("Bookmark %s is not found in %s", name, path)
+	}
+	return bookmark, nil
+}
+
+// Load configuration file and override by command line options
+func setupConfig() error {
+	path := cli.config
+	if path == "" {
+		path = configPath()
+	}
+	loaded, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("Failed to load %s: %s", path, err)
+	}
+
+	if cli.profile != "" {
+		loaded.Profile = cli.profile
+	}
+	loaded.UseProfile(loaded.Profile)
+	if cli.region != "" {
+		loaded.Region = cli.region
+	}
+	if cli.endpoint != "" {
+		loaded.Endpoint = cli.endpoint
+	}
+	if err := loaded.Apply(); err != nil {
+		return fmt.Errorf("Invalid configuration in %s: %s", path, err)
+	}
+	config = loaded
+	return nil
+}
+
+// Main function
+func main() {
+	flag.Parse()
+	if cli.help {
+		showUsage()
+		os.Exit(0)
+	}
+
+	location := cli.bucket
+	if cli.bookmark != "" {
+		bookmark, err := findBookmark(cli.bookmark)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		location = bookmark.URI
+		// Command line options still take precedence
+		if cli.profile == "" {
+			cli.profile = bookmark.Profile
+		}
+		if cli.region == "" {
+			cli.region = bookmark.Region
+		}
+	}
+
+	if err := setupConfig(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	defer logger.Close()
+	service := newService(config.Profile, config.Region, config.Endpoint)
+	bucket, prefix := parseBucketOption(location)
+	app, err := NewApp(service, bucket, prefix)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// User metadata header prefix
+const metaPrefix = "x-amz-meta-"
+
+// System headers which can be edited
+var editableHeaders = []string{
+	"Content-Type",
+	"Cache-Control",
+	"Content-Encoding",
+	"Content-Disposition",
+	"Content-Language",
+}
+
+// Object metadata struct which holds system headers and user metadata
+type Metadata map[string]string
+
+// Create metadata from HeadObject result
+func metadataFromHead(head *s3.HeadObjectOutput) Metadata {
+	m := Metadata{}
+	set := func(name string, v *string) {
+		if v != nil && *v != "" {
+			m[name] = *v
+		}
+	}
+	set("Content-Type", head.ContentType)
+	set("Cache-Control", head.CacheControl)
+	set("Content-Encoding", head.ContentEncoding)
+	set("Content-Disposition", head.ContentDisposition)
+	set("Content-Language", head.ContentLanguage)
+	for k, v := range head.Metadata {
+		set(metaPrefix+strings.ToLower(k), v)
+	}
+	return m
+}
+
+// Get sorted header names
+func (m Metadata) names() []string {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Metadata changes, nil value means removing
+type MetadataChange map[string]*string
+
+// Apply changes and returns new metadata and difference lines
+func (m Metadata) Apply(change MetadataChange) (Metadata, []string) {
+	updated := Metadata{}
+	for k, v := range m {
+		updated[k] = v
+	}
+	names := []string{}
+	for name := range change {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	diff := []string{}
+	for _, name := range names {
+		old, exists := m[name]
+		value := change[name]
+		switch {
+		case value == nil && exists:
+			delete(updated, name)
+			diff = append(diff, fmt.Sprintf("  - %s: %s", name, old))
+		case value == nil:
+			continue
+		case !exists:
+			updated[name] = *value
+			diff = append(diff, fmt.Sprintf("  + %s: %s", name, *value))
+		case old != *value:
+			updated[name] = *value
+			diff = append(diff, fmt.Sprintf("  ~ %s: %s -> %s", name, old, *value))
+		}
+	}
+	return updated, diff
+}
+
+// Object ACL grant headers. Empty grants mean the default private ACL
+type Grants struct {
+
+	// Grantees of FULL_CONTROL permission
+	FullControl *string
+
+	// Grantees of READ permission
+	Read *string
+
+	// Grantees of READ_ACP permission
+	ReadACP *string
+
+	// Grantees of WRITE_ACP permission
+	WriteACP *string
+}
+
+// Make grant headers from object ACL. ACL which grants FULL_CONTROL to owner only makes empty grants
+func grantsFromACL(acl *s3.GetObjectAclOutput) Grants {
+	grants := Grants{}
+	if len(acl.Grants) == 1 && acl.Owner != nil {
+		g := acl.Grants[0]
+		if aws.StringValue(g.Permission) == s3.PermissionFullControl && aws.StringValue(g.Grantee.ID) == aws.StringValue(acl.Owner.ID) {
+			return grants
+		}
+	}
+	specs := map[string][]string{}
+	for _, g := range acl.Grants {
+		var spec string
+		switch {
+		case g.Grantee.URI != nil:
+			spec = fmt.Sprintf(`uri="%s"`, *g.Grantee.URI)
+		case g.Grantee.EmailAddress != nil:
+			spec = fmt.Sprintf(`emailAddress="%s"`, *g.Grantee.EmailAddress)
+		default:
+			spec = fmt.Sprintf(`id="%s"`, aws.StringValue(g.Grantee.ID))
+		}
+		permission := aws.StringValue(g.Permission)
+		specs[permission] = append(specs[permission], spec)
+	}
+	join := func(permission string) *string {
+		if len(specs[permission]) == 0 {
+			return nil
+		}
+		return aws.String(strings.Join(specs[permission], ", "))
+	}
+	grants.FullControl = join(s3.PermissionFullControl)
+	grants.Read = join(s3.PermissionRead)
+	grants.ReadACP = join(s3.PermissionReadAcp)
+	grants.WriteACP = join(s3.PermissionWriteAcp)
+	return grants
+}
+
+// Fetch object ACL grants which are kept on replacing object
+func fetchGrants(service *s3.S3, bucket, key, versionId string) (Grants, error) {
+	input := &s3.GetObjectAclInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionId != "" {
+		input = input.SetVersionId(versionId)
+	}
+	acl, err := service.GetObjectAcl(input)
+	if err != nil {
+		return Grants{}, err
+	}
+	return grantsFromACL(acl), nil
+}
+
+// Make self CopyObject input which replaces metadata and keeps storage class, encryption and ACL grants
+func (m Metadata) copyInput(bucket, key string, head *s3.HeadObjectOutput, grants Grants) *s3.CopyObjectInput {
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		CopySource:        aws.String(copySource(bucket, key, "")),
+		MetadataDirective: aws.String(s3.MetadataDirectiveReplace),
+		Metadata:          map[string]*string{},
+		StorageClass:      head.StorageClass,
+		GrantFullControl:  grants.FullControl,
+		GrantRead:         grants.Read,
+		GrantReadACP:      grants.ReadACP,
+		GrantWriteACP:     grants.WriteACP,
+	}
+	if head.ETag != nil {
+		input.CopySourceIfMatch = head.ETag
+	}
+	if head.ServerSideEncryption != nil {
+		input.ServerSideEncryption = head.ServerSideEncryption
+		input.SSEKMSKeyId = head.SSEKMSKeyId
+	}
+	for _, name := range m.names() {
+		value := aws.String(m[name])
+		switch name {
+		case "Content-Type":
+			input.ContentType = value
+		case "Cache-Control":
+			input.CacheControl = value
+		case "Content-Encoding":
+			input.ContentEncoding = value
+		case "Content-Disposition":
+			input.ContentDisposition = value
+		case "Content-Language":
+			input.ContentLanguage = value
+		default:
+			input.Metadata[strings.TrimPrefix(name, metaPrefix)] = value
+		}
+	}
+	return input
+}
+
+// Make PutObject input which keeps metadata, storage class, encryption and ACL grants of the original object
+func (m Metadata) putInput(bucket, key string, head *s3.HeadObjectOutput, grants Grants, body []byte) *s3.PutObjectInput {
+	c := m.copyInput(bucket, key, head, grants)
+	return &s3.PutObjectInput{
+		Bucket:               c.Bucket,
+		Key:                  c.Key,
+		Body:                 bytes.NewReader(body),
+		Metadata:             c.Metadata,
+		StorageClass:         c.StorageClass,
+		ServerSideEncryption: c.ServerSideEncryption,
+		SSEKMSKeyId:          c.SSEKMSKeyId,
+		ContentType:          c.ContentType,
+		CacheControl:         c.CacheControl,
+		ContentEncoding:      c.ContentEncoding,
+		ContentDisposition:   c.ContentDisposition,
+		ContentLanguage:      c.ContentLanguage,
+		GrantFullControl:     c.GrantFullControl,
+		GrantRead:            c.GrantRead,
+		GrantReadACP:         c.GrantReadACP,
+		GrantWriteACP:        c.GrantWriteACP,
+	}
+}
+
+// Edit metadata by form. If head is nil, edit for multiple objects and unchanged fields are kept
+func editMetadata(head *s3.HeadObjectOutput, selector *Selector, status *Status) (MetadataChange, bool, error) {
+	current := Metadata{}
+	if head != nil {
+		current = metadataFromHead(head)
+	}
+
+	form := NewForm("Edit metadata (empty value removes header)", selector, status).Extendable(metaPrefix)
+	for _, name := range editableHeaders {
+		field := form.Field(name, current[name])
+		if head == nil {
+			field.placeholder = "(keep)"
+		}
+	}
+	for _, name := range current.names() {
+		if strings.HasPrefix(name, metaPrefix) {
+			form.Field(name, current[name])
+		}
+	}
+
+	saved, err := form.Do()
+	if err != nil || !saved {
+		return nil, false, err
+	}
+
+	change := MetadataChange{}
+	for _, field := range form.fields {
+		if !field.changed {
+			continue
+		}
+		if field.value == "" {
+			change[field.label] = nil
+		} else {
+			change[field.label] = aws.String(field.value)
+		}
+	}
+	return change, len(change) > 0, nil
+}
+
+// Apply metadata change to objects with preview
+func applyMetadata(service *s3.S3, bucket string, keys []string, change MetadataChange, selector *Selector, status *Status) error {
+	type target struct {
+		key      string
+		head     *s3.HeadObjectOutput
+		metadata Metadata
+		grants   Grants
+	}
+	targets := []target{}
+	preview := []string{}
+	for i, key := range keys {
+		status.Message(fmt.Sprintf("Inspecting metadata %d / %d ...", i+1, len(keys)), 0)
+		head, err := service.HeadObject(&s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return err
+		}
+		updated, diff := metadataFromHead(head).Apply(change)
+		if len(diff) == 0 {
+			continue
+		}
+		// Replacing metadata resets ACL, so grants are copied if readable
+		grants, err := fetchGrants(service, bucket, key, "")
+		if err != nil {
+			logger.log(fmt.Sprintf("Failed to get ACL of %s: %s", key, err))
+			diff = append(diff, fmt.Sprintf("  ! ACL is not readable (%s), it will be reset to private", errorCode(err)))
+		}
+		targets = append(targets, target{key: key, head: head, metadata: updated, grants: grants})
+		preview = append(preview, key)
+		preview = append(preview, diff...)
+	}
+	if len(targets) == 0 {
+		<-status.Warn("Nothing to change", 1)
+		return nil
+	}
+
+	message := fmt.Sprintf("Apply metadata changes to %d objects? (Enter: apply, Esc: cancel)", len(targets))
+	if !selector.Preview(preview, message) {
+		return nil
+	}
+	failed, err := parallel(len(targets), func(i int) error {
+		t := targets[i]
+		_, err := service.CopyObject(t.metadata.copyInput(bucket, t.key, t.head, t.grants))
+		return err
+	}, func(done int) {
+		status.Info(fmt.Sprintf("Updating metadata %d / %d ...", done, len(targets)), 0)
+	})
+	if err != nil {
+		<-status.Failure(fmt.Sprintf("Failed to update metadata of %s", targets[failed].key), err, 2)
+		return nil
+	}
+	<-status.Info(fmt.Sprintf("Updated metadata of %d objects", len(targets)), 1)
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestMetadataApply(t *testing.T) {
+	m := Metadata{
+		"Content-Type":       "text/plain",
+		"x-amz-meta-owner":   "alice",
+		"x-amz-meta-removed": "yes",
+	}
+	updated, diff := m.Apply(MetadataChange{
+		"Content-Type":       aws.String("application/json"),
+		"Cache-Control":      aws.String("max-age=60"),
+		"x-amz-meta-owner":   aws.String("alice"),
+		"x-amz-meta-removed": nil,
+	})
+	if len(diff) != 3 {
+		t.Errorf("diff length expected 3, actual %d: %v", len(diff), diff)
+	}
+	if updated["Content-Type"] != "application/json" || updated["Cache-Control"] != "max-age=60" {
+		t.Errorf("unexpected updated headers %v", updated)
+	}
+	if _, ok := updated["x-amz-meta-removed"]; ok {
+		t.Errorf("x-amz-meta-removed expected to be removed")
+	}
+	if m["Content-Type"] != "text/plain" {
+		t.Errorf("original metadata must not be modified")
+	}
+}
+
+func TestMetadataCopyInput(t *testing.T) {
+	head := &s3.HeadObjectOutput{
+		ETag:         aws.String(`"etag"`),
+		StorageClass: aws.String("STANDARD_IA"),
+	}
+	m := Metadata{
+		"Content-Type":     "text/html",
+		"x-amz-meta-owner": "alice",
+	}
+	grants := Grants{Read: aws.String(`uri="http://acs.amazonaws.com/groups/global/AllUsers"`)}
+	input := m.copyInput("bucket", "dir/index.html", head, grants)
+	if *input.MetadataDirective != s3.MetadataDirectiveReplace {
+		t.Errorf("metadata directive expected REPLACE, actual %s", *input.MetadataDirective)
+	}
+	if *input.ContentType != "text/html" || *input.Metadata["owner"] != "alice" {
+		t.Errorf("unexpected copy input %v", input)
+	}
+	if *input.StorageClass != "STANDARD_IA" || *input.CopySourceIfMatch != `"etag"` {
+		t.Errorf("storage class and etag must be kept")
+	}
+	if input.GrantRead != grants.Read || input.GrantFullControl != nil {
+		t.Errorf("ACL grants must be kept")
+	}
+}
+
+func TestGrantsFromACL(t *testing.T) {
+	owner := &s3.Owner{ID: aws.String("owner")}
+	grant := func(grantee *s3.Grantee, permission string) *s3.Grant {
+		return &s3.Grant{Grantee: grantee, Permission: aws.String(permission)}
+	}
+	private := &s3.GetObjectAclOutput{
+		Owner:  owner,
+		Grants: []*s3.Grant{grant(&s3.Grantee{ID: aws.String("owner")}, s3.PermissionFullControl)},
+	}
+	if grants := grantsFromACL(private); grants != (Grants{}) {
+		t.Errorf("private ACL expected empty grants, actual %v", grants)
+	}
+
+	public := &s3.GetObjectAclOutput{
+		Owner: owner,
+		Grants: []*s3.Grant{
+			grant(&s3.Grantee{ID: aws.String("owner")}, s3.PermissionFullControl),
+			grant(&s3.Grantee{URI: aws.String("http://acs.amazonaws.com/groups/global/AllUsers")}, s3.PermissionRead),
+			grant(&s3.Grantee{EmailAddress: aws.String("bob@example.com")}, s3.PermissionRead),
+		},
+	}
+	grants := grantsFromACL(public)
+	if aws.StringValue(grants.FullControl) != `id="owner"` {
+		t.Errorf("unexpected full control grant %s", aws.StringValue(grants.FullControl))
+	}
+	expected := `uri="http://acs.amazonaws.com/groups/global/AllUsers", emailAddress="bob@example.com"`
+	if aws.StringValue(grants.Read) != expected {
+		t.Errorf("expected read grant %s, actual %s", expected, aws.StringValue(grants.Read))
+	}
+	if grants.ReadACP != nil || grants.WriteACP != nil {
+		t.Errorf("unexpected ACP grants %v", grants)
+	}
+}
+
+func TestMetadataPutInput(t *testing.T) {
+	head := &s3.HeadObjectOutput{
+		StorageClass:         aws.String("STANDARD_IA"),
+		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAes256),
+	}
+	m := Metadata{
+		"Content-Type":     "application/json",
+		"Cache-Control":    "no-cache",
+		"x-amz-meta-owner": "alice",
+	}
+	grants := Grants{FullControl: aws.String(`id="owner"`), ReadACP: aws.String(`id="auditor"`)}
+	input := m.putInput("bucket", "config.json", head, grants, []byte("{}"))
+	if *input.ContentType != "application/json" || *input.CacheControl != "no-cache" || *input.Metadata["owner"] != "alice" {
+		t.Errorf("metadata must be kept, actual %v", input)
+	}
+	if *input.StorageClass != "STANDARD_IA" || *input.ServerSideEncryption != s3.ServerSideEncryptionAes256 {
+		t.Errorf("storage class and encryption must be kept")
+	}
+	if input.GrantFullControl != grants.FullControl || input.GrantReadACP != grants.ReadACP {
+		t.Errorf("ACL grants must be kept")
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+// Screen kind on navigation stack
+type ScreenKind int
+
+const (
+	BucketListScreen ScreenKind = iota
+	ObjectListScreen
+	ActionMenuScreen
+	ViewerScreen
+	DialogScreen
+)
+
+// Screen on navigation stack
+type Screen struct {
+
+	// Screen kind
+	kind ScreenKind
+
+	// Location and listing mode which object list screen has listed successfully lastly
+	listed   *Visit
+	versions bool
+	deleted  bool
+
+	// Object action for action menu screen
+	action *Action
+
+	// File name and content for viewer screen
+	name    string
+	content []byte
+
+	// Dialog for dialog screen. Dialog screen is popped after running,
+	// then returned navigation is applied to the screen under the dialog
+	dialog func() (Navigation, error)
+}
+
+// Navigation operation which screen returns
+type NavigationOp int
+
+const (
+	// Run current screen again
+	NavStay NavigationOp = iota
+	// Push screen onto current screen
+	NavPush
+	// Back to previous screen
+	NavPop
+	// Replace current screen
+	NavReplace
+	// Quit application
+	NavQuit
+)
+
+// Navigation from screen
+type Navigation struct {
+
+	// Operation
+	op NavigationOp
+
+	// Screen to push or replace with
+	screen *Screen
+}
+
+// Stay on current screen
+func navStay() Navigation {
+	return Navigation{op: NavStay}
+}
+
+// Push screen
+func navPush(screen *Screen) Navigation {
+	return Navigation{op: NavPush, screen: screen}
+}
+
+// Back to previous screen
+func navPop() Navigation {
+	return Navigation{op: NavPop}
+}
+
+// Replace current screen
+func navReplace(screen *Screen) Navigation {
+	return Navigation{op: NavReplace, screen: screen}
+}
+
+// Show dialog on current screen
+func navDialog(run func() (Navigation, error)) Navigation {
+	return navPush(&Screen{kind: DialogScreen, dialog: run})
+}
+
+// Get current screen
+func (a *App) current() *Screen {
+	return a.screens[len(a.screens)-1]
+}
+
+// Apply navigation to screen stack
+func (a *App) apply(nav Navigation) {
+	switch nav.op {
+	case NavPush:
+		a.screens = append(a.screens, nav.screen)
+	case NavPop:
+		a.popScreen()
+	case NavReplace:
+		a.popScreen()
+		a.screens = append(a.screens, nav.screen)
+	case NavQuit:
+		for len(a.screens) > 0 {
+			a.popScreen()
+		}
+	}
+}
+
+// Remove current screen, polling of action menu is stopped
+func (a *App) popScreen() {
+	if screen := a.current(); screen.kind == ActionMenuScreen {
+		screen.action.Stop()
+	}
+	a.screens = a.screens[0 : len(a.screens)-1]
+}
+
+// Run screens until navigation stack becomes empty.
+// Error is shown on status and navigation continues unless screen quits application
+func (a *App) navigate() error {
+	for len(a.screens) > 0 {
+		nav, err := a.runScreen(a.current())
+		if err != nil && nav.op == NavQuit {
+			a.apply(nav)
+			return err
+		} else if err != nil {
+			logger.log("Operation failed: " + err.Error())
+			<-a.status.Failure("Operation failed", err, 2)
+		}
+		a.apply(nav)
+	}
+	return nil
+}
+
+// Quit application when choosing is interrupted, otherwise stay on current screen
+func quitOnInterrupt(err error) (Navigation, error) {
+	if err == ErrInterrupted {
+		return Navigation{op: NavQuit}, err
+	}
+	return navStay(), nil
+}
+
+// Run screen and get navigation
+func (a *App) runScreen(screen *Screen) (Navigation, error) {
+	switch screen.kind {
+	case BucketListScreen:
+		return a.chooseBuckets()
+	case ObjectListScreen:
+		return a.chooseObject(screen)
+	case ActionMenuScreen:
+		return a.chooseAction(screen.action)
+	case ViewerScreen:
+		if err := NewViewer(a.selector, a.status, 2).View(screen.name, screen.content); err != nil {
+			<-a.status.Error(err.Error(), 1)
+		}
+		return navPop(), nil
+	case DialogScreen:
+		// Pop dialog before applying its navigation
+		a.apply(navPop())
+		return screen.dialog()
+	}
+	return navPop(), nil
+}
+
+// Go back to bucket list. Bucket list screen is under object list unless bucket is given at startup
+func (a *App) toBucketList() Navigation {
+	if n := len(a.screens); n >= 2 && a.screens[n-2].kind == BucketListScreen {
+		return navPop()
+	}
+	return navReplace(&Screen{kind: BucketListScreen})
+}
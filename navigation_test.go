@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNavigationStack(t *testing.T) {
+	app := &App{screens: []*Screen{{kind: BucketListScreen}}}
+	assert := func(name string, kinds ...ScreenKind) {
+		t.Helper()
+		if len(app.screens) != len(kinds) {
+			t.Fatalf("%s: expected %d screens, actual %d", name, len(kinds), len(app.screens))
+		}
+		for i, kind := range kinds {
+			if app.screens[i].kind != kind {
+				t.Errorf("%s: expected screen %d is %d, actual %d", name, i, kind, app.screens[i].kind)
+			}
+		}
+	}
+
+	app.apply(navPush(&Screen{kind: ObjectListScreen}))
+	app.apply(navPush(&Screen{kind: ActionMenuScreen, action: &Action{}}))
+	app.apply(navPush(&Screen{kind: ViewerScreen}))
+	assert("push", BucketListScreen, ObjectListScreen, ActionMenuScreen, ViewerScreen)
+	app.apply(navStay())
+	assert("stay", BucketListScreen, ObjectListScreen, ActionMenuScreen, ViewerScreen)
+	app.apply(navPop())
+	app.apply(navPop())
+	assert("pop", BucketListScreen, ObjectListScreen)
+
+	if nav := app.toBucketList(); nav.op != NavPop {
+		t.Errorf("expected going back to bucket list under object list, actual %d", nav.op)
+	}
+	// Bucket is given at startup, so object list is the first screen
+	app.screens = []*Screen{{kind: ObjectListScreen}}
+	app.apply(app.toBucketList())
+	assert("replace", BucketListScreen)
+
+	app.apply(navPush(&Screen{kind: ObjectListScreen}))
+	app.apply(Navigation{op: NavQuit})
+	assert("quit")
+}
+
+func TestNavigationDialog(t *testing.T) {
+	app := &App{screens: []*Screen{{kind: BucketListScreen}}}
+	app.apply(navDialog(func() (Navigation, error) {
+		return navPush(&Screen{kind: ObjectListScreen}), nil
+	}))
+	nav, err := app.runScreen(app.current())
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	// Dialog is popped, then its navigation is applied to the screen under the dialog
+	app.apply(nav)
+	if len(app.screens) != 2 || app.screens[0].kind != BucketListScreen || app.screens[1].kind != ObjectListScreen {
+		t.Errorf("expected bucket list and object list, actual %v", app.screens)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/mattn/go-runewidth"
@@ -20,22 +21,39 @@ type Object struct {
 	// Last modified time
 	lastModified time.Time
 
+	// Storage class
+	storageClass string
+
 	// directroy flag
 	dir bool
 
 	// parent flag
 	parent bool
 
+	// deleted flag, latest version is delete marker
+	deleted bool
+
 	Writer
 }
 
 // Create new object pointer
-func NewObject(key string, size int64, lastModified time.Time, dir bool) *Object {
+func NewObject(key string, size int64, lastModified time.Time, storageClass string, dir bool) *Object {
 	return &Object{
 		size:         size,
+		key:          key,
+		lastModified: lastModified,
+		storageClass: storageClass,
+		dir:          dir,
+	}
+}
+
+// Create new object pointer which is hidden by delete marker
+func NewDeletedObject(key string, lastModified time.Time, dir bool) *Object {
+	return &Object{
 		key:          key,
 		lastModified: lastModified,
 		dir:          dir,
+		deleted:      true,
 	}
 }
 
@@ -51,10 +69,14 @@ func NewParentObject() *Object {
 func (o *Object) String() string {
 	if o.parent {
 		return ""
+	} else if o.deleted && o.dir {
+		return fmt.Sprintf("%s %10s  %s/ (deleted)", localTime(o.lastModified), "-", o.key)
+	} else if o.deleted {
+		return fmt.Sprintf("%s %10s  %s (deleted)", localTime(o.lastModified), "-", o.key)
 	} else if o.dir {
-		return fmt.Sprintf("%s %10s  %s/", utcToJst(o.lastModified), "-", o.key)
+		return fmt.Sprintf("%s %10s  %s/", localTime(o.lastModified), "-", o.key)
 	} else {
-		return fmt.Sprintf("%s %10d  %s", utcToJst(o.lastModified), o.size, o.key)
+		return fmt.Sprintf("%s %10d  %s %s", localTime(o.lastModified), o.size, shortStorageClass(o.storageClass), o.key)
 	}
 }
 
@@ -64,52 +86,84 @@ func (o *Object) Write(y int, filter string) {
 	// parent directory, write "../"
 	if o.parent {
 		for _, r := range []rune(o.key) {
-			termbox.SetCell(i, y, r, termbox.ColorWhite, termbox.ColorBlue)
+			termbox.SetCell(i, y, r, theme.Parent.Fg, theme.Parent.Bg)
 			i++
 		}
-		// Write as directory
-	} else if o.dir {
-		for _, r := range []rune(utcToJst(o.lastModified)) {
-			termbox.SetCell(i, y, r, termbox.ColorWhite, termbox.ColorDefault)
+		// Write as deleted directory or object
+	} else if o.deleted {
+		for _, r := range []rune(localTime(o.lastModified)) {
+			termbox.SetCell(i, y, r, theme.Date.Fg, theme.Date.Bg)
 			i++
 		}
-		for _, r := range []rune(fmt.Sprintf(" %12s    ", "-")) {
-			termbox.SetCell(i, y, r, termbox.ColorCyan, termbox.ColorDefault)
+		i = writeColumns(i, y, "-", "")
+
+		name := o.key
+		if o.dir {
+			name += "/"
+		}
+		first, last := findHighlightRange(o.key, filter)
+		for j, r := range []rune(name + " (deleted)") {
+			style := theme.Deleted
+			if j >= first && j < last {
+				style = theme.Highlight
+			}
+			termbox.SetCell(i, y, r, style.Fg, style.Bg)
+			i += runewidth.RuneWidth(r)
+		}
+		// Write as directory
+	} else if o.dir {
+		for _, r := range []rune(localTime(o.lastModified)) {
+			termbox.SetCell(i, y, r, theme.Date.Fg, theme.Date.Bg)
 			i++
 		}
+		i = writeColumns(i, y, "-", "")
 
 		first, last := findHighlightRange(o.key, filter)
 		for j, r := range []rune(fmt.Sprintf("%s/", o.key)) {
-			color := termbox.ColorGreen
+			style := theme.Directory
 			if j >= first && j < last {
-				color = termbox.ColorYellow
+				style = theme.Highlight
 			}
-			termbox.SetCell(i, y, r, color|termbox.AttrBold, termbox.ColorDefault)
+			termbox.SetCell(i, y, r, style.Fg, style.Bg)
 			i += runewidth.RuneWidth(r)
 		}
 		// Write as object
 	} else {
-		for _, r := range []rune(utcToJst(o.lastModified)) {
-			termbox.SetCell(i, y, r, termbox.ColorWhite, termbox.ColorDefault)
-			i++
-		}
-		for _, r := range []rune(fmt.Sprintf(" %12d    ", o.size)) {
-			termbox.SetCell(i, y, r, termbox.ColorCyan, termbox.ColorDefault)
+		for _, r := range []rune(localTime(o.lastModified)) {
+			termbox.SetCell(i, y, r, theme.Date.Fg, theme.Date.Bg)
 			i++
 		}
+		i = writeColumns(i, y, fmt.Sprint(o.size), o.storageClass)
 
 		first, last := findHighlightRange(o.key, filter)
 		for j, r := range []rune(fmt.Sprintf("%s", o.key)) {
-			color := termbox.ColorWhite
+			style := theme.Text
 			if j >= first && j < last {
-				color = termbox.ColorYellow
+				style = theme.Highlight
 			}
-			termbox.SetCell(i, y, r, color, termbox.ColorDefault)
+			termbox.SetCell(i, y, r, style.Fg, style.Bg)
 			i += runewidth.RuneWidth(r)
 		}
 	}
 }
 
+// Write size and storage class columns, returns next x position
+func writeColumns(x, y int, size, storageClass string) int {
+	for _, r := range []rune(fmt.Sprintf(" %12s  ", size)) {
+		termbox.SetCell(x, y, r, theme.Size.Fg, theme.Size.Bg)
+		x++
+	}
+	style := theme.Storage
+	if isArchived(storageClass) {
+		style = theme.Archived
+	}
+	for _, r := range []rune(fmt.Sprintf("%-8s  ", shortStorageClass(storageClass))) {
+		termbox.SetCell(x, y, r, style.Fg, style.Bg)
+		x++
+	}
+	return x
+}
+
 // Define Object list type
 type Objects []*Object
 
@@ -122,3 +176,23 @@ func (o Objects) Selectable() Selectable {
 
 	return s
 }
+
+// Object list sort orders, "name" keeps listed order
+var objectSortOrders = []string{"name", "date", "size"}
+
+// Sort objects by newest or largest, directories are kept first
+func sortObjects(objects Objects, order string) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		a, b := objects[i], objects[j]
+		if a.dir != b.dir {
+			return a.dir
+		}
+		switch order {
+		case "date":
+			return a.lastModified.After(b.lastModified)
+		case "size":
+			return a.size > b.size
+		}
+		return false
+	})
+}
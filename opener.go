@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"mime"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// Placeholder of file path in opener command
+const openerPlaceholder = "{}"
+
+// Find opener commands in priority order: extension, MIME type, MIME wildcard and "*"
+func findOpeners(openers map[string]string, name, contentType string) []string {
+	ext := strings.ToLower(path.Ext(name))
+	mimeType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mimeType == "" || mimeType == "binary/octet-stream" || mimeType == "application/octet-stream" {
+		// Generic content type, guess from extension instead
+		if guessed, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+			mimeType = guessed
+		}
+	}
+
+	keys := []string{}
+	if ext != "" {
+		keys = append(keys, ext)
+	}
+	if mimeType != "" {
+		keys = append(keys, mimeType, strings.SplitN(mimeType, "/", 2)[0]+"/*")
+	}
+	keys = append(keys, "*")
+
+	commands := []string{}
+	found := map[string]struct{}{}
+	for _, key := range keys {
+		command, ok := openers[key]
+		if !ok {
+			continue
+		}
+		if _, exists := found[command]; exists {
+			continue
+		}
+		found[command] = struct{}{}
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// Make shell script which passes file path as $1. Path is appended if command doesn't have placeholder
+func openerScript(command string) string {
+	if strings.Contains(command, openerPlaceholder) {
+		return strings.Replace(command, openerPlaceholder, `"$1"`, -1)
+	}
+	return command + ` "$1"`
+}
+
+// Run opener command with suspending termbox, and wait for Enter to return
+func openWith(selector *Selector, command, file string) error {
+	return selector.Suspend(func() error {
+		cmd := exec.Command("sh", "-c", openerScript(command), "ls3", file)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err := cmd.Run()
+		if err != nil {
+			fmt.Printf("\n%s: %s\n", command, err)
+		}
+		// Keep output of command, and also give detached program time to read the file
+		fmt.Print("\n[Press Enter to return to ls3]")
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return err
+	})
+}
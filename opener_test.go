@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindOpeners(t *testing.T) {
+	openers := map[string]string{
+		".json":   "jq . {}",
+		"image/*": "xdg-open",
+		"*":       "less",
+	}
+	if actual := findOpeners(openers, "data/config.JSON", "application/json"); !reflect.DeepEqual(actual, []string{"jq . {}", "less"}) {
+		t.Errorf("unexpected openers %v", actual)
+	}
+	if actual := findOpeners(openers, "photo.png", "binary/octet-stream"); !reflect.DeepEqual(actual, []string{"xdg-open", "less"}) {
+		t.Errorf("MIME type expected to be guessed from extension, actual %v", actual)
+	}
+	if actual := findOpeners(map[string]string{}, "a.txt", "text/plain"); len(actual) != 0 {
+		t.Errorf("expected no openers, actual %v", actual)
+	}
+}
+
+func TestOpenerScript(t *testing.T) {
+	if script := openerScript("jq . {} | less"); script != `jq . "$1" | less` {
+		t.Errorf("unexpected script %s", script)
+	}
+	if script := openerScript("xdg-open"); script != `xdg-open "$1"` {
+		t.Errorf("unexpected script %s", script)
+	}
+}
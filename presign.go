@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nsf/termbox-go"
+)
+
+// Expiry choices for presigned URL, SigV4 allows 7 days at most
+var presignExpiries = []string{"15m", "1h", "6h", "12h", "24h", "72h", "168h"}
+
+// Choose presigned URL expiry by form
+func chooseExpiry(title string, selector *Selector, status *Status) (time.Duration, bool, error) {
+	form := NewForm(title, selector, status)
+	form.Field("Expires In", "1h", presignExpiries...)
+	saved, err := form.Do()
+	if err != nil || !saved {
+		return 0, false, err
+	}
+	expiry, err := time.ParseDuration(form.Value("Expires In"))
+	if err != nil {
+		return 0, false, err
+	}
+	return expiry, true, nil
+}
+
+// Generate presigned GET URL
+func presignGet(service *s3.S3, bucket, key, versionId string, expiry time.Duration) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionId != "" {
+		input = input.SetVersionId(versionId)
+	}
+	req, _ := service.GetObjectRequest(input)
+	return req.Presign(expiry)
+}
+
+// Generate presigned PUT URL
+func presignPut(service *s3.S3, bucket, key string, expiry time.Duration) (string, error) {
+	req, _ := service.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return req.Presign(expiry)
+}
+
+// Show presigned URL and copy it to clipboard
+func showURL(url string, expiry time.Duration, selector *Selector, status *Status) {
+	width, _ := termbox.Size()
+	width -= 10
+	if width < 20 {
+		width = 20
+	}
+	lines := []string{}
+	for r := []rune(url); len(r) > 0; {
+		end := width
+		if end > len(r) {
+			end = len(r)
+		}
+		lines = append(lines, string(r[0:end]))
+		r = r[end:]
+	}
+
+	message := fmt.Sprintf("URL expires in %s, copied to clipboard (Enter/Esc: close)", expiry)
+	if err := copyToClipboard(url); err != nil {
+		message = fmt.Sprintf("URL expires in %s, failed to copy to clipboard (Enter/Esc: close)", expiry)
+	}
+	selector.Preview(lines, message)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Retryer which retries throttling and 5xx errors with exponential backoff, and logs retrying.
+// Retrying is not shown on status because requests are sent from worker goroutines
+type Retryer struct {
+	client.DefaultRetryer
+}
+
+// Create Retryer with max retry count
+func NewRetryer(retries int) Retryer {
+	return Retryer{
+		DefaultRetryer: client.DefaultRetryer{
+			NumMaxRetries:    retries,
+			MinRetryDelay:    200 * time.Millisecond,
+			MaxRetryDelay:    5 * time.Second,
+			MinThrottleDelay: 500 * time.Millisecond,
+			MaxThrottleDelay: 20 * time.Second,
+		},
+	}
+}
+
+// request.Retryer::RetryRules implementation, which is called only when request will be retried
+func (r Retryer) RetryRules(req *request.Request) time.Duration {
+	delay := r.DefaultRetryer.RetryRules(req)
+	message := fmt.Sprintf(
+		"%s failed by %s, retrying (%d/%d) after %.1fs ...",
+		req.Operation.Name, errorCode(req.Error), req.RetryCount+1, r.MaxRetries(), delay.Seconds(),
+	)
+	logger.log(message)
+	return delay
+}
+
+// Make error detail lines which are shown by error-details command
+func errorDetails(message string, err error) []string {
+	details := []string{message}
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return append(details, "Error: "+err.Error())
+	}
+	details = append(details, "Code: "+aerr.Code(), "Message: "+aerr.Message())
+	if rerr, ok := err.(awserr.RequestFailure); ok {
+		details = append(details,
+			fmt.Sprintf("Status code: %d", rerr.StatusCode()),
+			"Request ID: "+rerr.RequestID(),
+		)
+	}
+	if serr, ok := err.(s3.RequestFailure); ok && serr.HostID() != "" {
+		details = append(details, "Host ID: "+serr.HostID())
+	}
+	for cause := aerr.OrigErr(); cause != nil; {
+		details = append(details, "Cause: "+cause.Error())
+		next, ok := cause.(awserr.Error)
+		if !ok {
+			break
+		}
+		cause = next.OrigErr()
+	}
+	return details
+}
+
+// Create S3 client for config, requests are retried with backoff.
+// Retryer is set on client because copied aws.Config doesn't carry it
+func newClient(conf *aws.Config) *s3.S3 {
+	service := s3.New(session.Must(session.NewSession()), conf)
+	service.Retryer = NewRetryer(config.Retries)
+	return service
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+func TestRetryer(t *testing.T) {
+	retryer := NewRetryer(3)
+	tests := []struct {
+		status   int
+		code     string
+		expected bool
+	}{
+		{503, "SlowDown", true},
+		{500, "InternalError", true},
+		{429, "TooManyRequests", true},
+		{403, "AccessDenied", false},
+		{404, "NoSuchKey", false},
+	}
+	for _, tt := range tests {
+		req := &request.Request{
+			Operation:    &request.Operation{Name: "ListObjectsV2"},
+			HTTPResponse: &http.Response{StatusCode: tt.status, Header: http.Header{}},
+			Error:        awserr.NewRequestFailure(awserr.New(tt.code, "message", nil), tt.status, "request-id"),
+			Retryer:      retryer,
+		}
+		if actual := retryer.ShouldRetry(req); actual != tt.expected {
+			t.Errorf("%d %s: expected retry %t, actual %t", tt.status, tt.code, tt.expected, actual)
+		}
+		if tt.expected && retryer.RetryRules(req) <= 0 {
+			t.Errorf("%d %s: expected positive retry delay", tt.status, tt.code)
+		}
+	}
+	if NewRetryer(0).ShouldRetry(&request.Request{
+		HTTPResponse: &http.Response{StatusCode: 503},
+	}) {
+		t.Errorf("expected no retry when retries is 0")
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	err := awserr.NewRequestFailure(awserr.New("AccessDenied", "Access Denied", nil), 403, "ABC123")
+	expected := []string{
+		"Failed to list s3://foo/",
+		"Code: AccessDenied",
+		"Message: Access Denied",
+		"Status code: 403",
+		"Request ID: ABC123",
+	}
+	if actual := errorDetails("Failed to list s3://foo/", err); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, actual %v", expected, actual)
+	}
+
+	cause := awserr.New("RequestError", "send request failed", http.ErrHandlerTimeout)
+	expected = []string{
+		"Failed",
+		"Code: RequestError",
+		"Message: send request failed",
+		"Cause: " + http.ErrHandlerTimeout.Error(),
+	}
+	if actual := errorDetails("Failed", cause); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, actual %v", expected, actual)
+	}
+}
+
+func TestRegionalServiceRetryer(t *testing.T) {
+	service := newClient(aws.NewConfig().WithRegion("us-east-1"))
+	regional := regionalService(service, "eu-west-1")
+	if aws.StringValue(regional.Config.Region) != "eu-west-1" {
+		t.Errorf("expected eu-west-1 client, actual %s", aws.StringValue(regional.Config.Region))
+	}
+	if _, ok := regional.Retryer.(Retryer); !ok {
+		t.Errorf("expected regional client uses backoff retryer, actual %T", regional.Retryer)
+	}
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"github.com/mattn/go-runewidth"
 	"github.com/nsf/termbox-go"
-	"math"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Selectable items struct
@@ -17,6 +19,12 @@ type Selector struct {
 	// Flag of incremental search
 	enableFilter bool
 
+	// Flag of marking multiple items
+	enableMark bool
+
+	// Marked item indexes at last choosing
+	marked []int
+
 	// Duplicate guard
 	guard chan struct{}
 
@@ -37,8 +45,63 @@ type Selector struct {
 
 	// Key event channel
 	onKeyPress chan termbox.Event
+
+	// Update channel, received function is run on choosing goroutine
+	onUpdate chan func()
+
+	// Bound application commands which finish choosing
+	bindings map[Command]struct{}
+
+	// Pending key strokes of key sequence
+	pending []KeyStroke
+
+	// Suspend request channel, App event loop waits until received channel is closed
+	suspended chan chan struct{}
+
+	// Last left click time for detecting double click
+	lastClick time.Time
+
+	// X position of last header click
+	clickedX int
+
+	// Cursor and filter to restore at next choosing
+	initialView *ListView
+
+	// Cursor and filter at last choosing
+	lastView ListView
 }
 
+// Cursor position and filter of list
+type ListView struct {
+
+	// Item index of cursor
+	Cursor int
+
+	// Filter query
+	Filter string
+}
+
+// Interval of two clicks which is treated as double click
+const doubleClickInterval = 400 * time.Millisecond
+
+// Scrolling rows per mouse wheel tick
+const wheelRows = 3
+
+// Error which is returned when bound command key is pressed while choosing
+type KeyBindError struct {
+
+	// Bound command
+	Command Command
+}
+
+// error::Error implementation
+func (k *KeyBindError) Error() string {
+	return fmt.Sprintf("command %s pressed", k.Command)
+}
+
+// Error which is returned when choosing or prompt is canceled by back command
+var ErrInterrupted = fmt.Errorf("interrupted")
+
 // Struct pointer maker
 func NewSelector(rowOffset int, status *Status) *Selector {
 	width, height := termbox.Size()
@@ -52,6 +115,9 @@ func NewSelector(rowOffset int, status *Status) *Selector {
 		status:       status,
 		onResize:     make(chan struct{}, 1),
 		onKeyPress:   make(chan termbox.Event, 1),
+		onUpdate:     make(chan func()),
+		bindings:     make(map[Command]struct{}),
+		suspended:    make(chan chan struct{}, 1),
 	}
 }
 
@@ -67,6 +133,202 @@ func (s *Selector) WithFilter() *Selector {
 	return s
 }
 
+// Switch enabling mark
+func (s *Selector) WithMark() *Selector {
+	s.enableMark = true
+	return s
+}
+
+// Switch disabling mark
+func (s *Selector) WithOutMark() *Selector {
+	s.enableMark = false
+	return s
+}
+
+// Restore cursor and filter at next choosing
+func (s *Selector) WithView(view ListView) *Selector {
+	s.initialView = &view
+	return s
+}
+
+// Get cursor and filter at last choosing
+func (s *Selector) LastView() ListView {
+	return s.lastView
+}
+
+// Get marked item indexes at last choosing
+func (s *Selector) Marked() []int {
+	return s.marked
+}
+
+// Bind application commands which finish choosing with KeyBindError
+func (s *Selector) Bind(commands ...Command) *Selector {
+	for _, c := range commands {
+		s.bindings[c] = struct{}{}
+	}
+	return s
+}
+
+// Unbind all bound commands
+func (s *Selector) Unbind() *Selector {
+	s.bindings = make(map[Command]struct{})
+	return s
+}
+
+// Check command is available in current choosing
+func (s *Selector) isEnabled(command Command) bool {
+	switch command {
+	case CmdDown, CmdUp, CmdPageDown, CmdPageUp, CmdHalfPageDown, CmdHalfPageUp, CmdTop, CmdBottom, CmdGotoRow,
+		CmdSelect, CmdBack, CmdHelp:
+		return true
+	case CmdFilter:
+		return s.enableFilter
+	case CmdMark:
+		return s.enableMark
+	case CmdErrorDetails:
+		return len(s.status.Details()) > 0
+	}
+	_, ok := s.bindings[command]
+	return ok
+}
+
+// Confirm with Cancel/Yes choices
+func (s *Selector) Confirm(message string) bool {
+	actions := ActionList{
+		ActionCommand{op: Back, name: "Cancel"},
+		ActionCommand{op: Confirm, name: "Yes, I'm sure"},
+	}
+
+	enableFilter := s.enableFilter
+	s.enableFilter = false
+	defer func() {
+		s.enableFilter = enableFilter
+	}()
+
+	s.status.Warn(message, 0)
+	index, err := s.Choose(actions.Selectable())
+	s.status.Clear()
+	return err == nil && actions[index].op == Confirm
+}
+
+// Preview lines and confirm by Enter key
+func (s *Selector) Preview(lines []string, message string) bool {
+	list := Selectable{}
+	for i, line := range lines {
+		list = append(list, &TextLine{number: i + 1, text: line})
+	}
+
+	enableFilter := s.enableFilter
+	s.enableFilter = false
+	defer func() {
+		s.enableFilter = enableFilter
+	}()
+
+	s.status.Warn(message, 0)
+	_, err := s.Choose(list)
+	s.status.Clear()
+	return err == nil
+}
+
+// Prompt text input on status row
+func (s *Selector) Prompt(message, initial string) (string, error) {
+	return s.PromptWithCompletion(message, initial, nil)
+}
+
+// Prompt text input on status row, Tab key completes input by candidates of complete function
+func (s *Selector) PromptWithCompletion(message, initial string, complete func(string) []string) (string, error) {
+	s.guard <- struct{}{}
+	defer func() {
+		<-s.guard
+	}()
+
+	input := []rune(initial)
+	candidates := []string{}
+	display := func() {
+		prompt := []rune(message + "> ")
+		line := string(prompt) + string(input)
+		if len(candidates) > 1 {
+			line += "  [" + strings.Join(candidates, " ") + "]"
+		}
+		s.status.Message(line, 0)
+		termbox.SetCursor(runewidth.StringWidth(string(prompt)+string(input)), s.status.row)
+		termbox.Flush()
+	}
+	defer func() {
+		termbox.HideCursor()
+		s.status.Clear()
+		termbox.Flush()
+	}()
+
+	display()
+	for {
+		select {
+		case <-s.onResize:
+			display()
+
+		case evt := <-s.onKeyPress:
+			switch {
+			case evt.Key == termbox.KeyCtrlC || evt.Key == termbox.KeyEsc:
+				return "", ErrInterrupted
+			case evt.Key == termbox.KeyEnter:
+				return string(input), nil
+			case evt.Key == termbox.KeyBackspace || evt.Key == termbox.KeyBackspace2:
+				if len(input) > 0 {
+					input = input[0 : len(input)-1]
+				}
+			case evt.Key == termbox.KeyCtrlU:
+				input = []rune{}
+			case evt.Key == termbox.KeyTab && complete != nil:
+				candidates = complete(string(input))
+				if len(candidates) > 0 {
+					input = []rune(commonPrefix(candidates))
+				}
+				display()
+				continue
+			case evt.Key == termbox.KeySpace:
+				input = append(input, ' ')
+			case evt.Ch > 0:
+				input = append(input, evt.Ch)
+			}
+			candidates = []string{}
+			display()
+		}
+	}
+}
+
+// Suspend termbox while running external program like $EDITOR, and re-initialize after that
+func (s *Selector) Suspend(run func() error) error {
+	resume := make(chan struct{})
+	s.suspended <- resume
+
+	// Interrupt polling event in App, then event loop waits for resume
+	termbox.Interrupt()
+	termbox.Close()
+	defer func() {
+		if err := termbox.Init(); err != nil {
+			logger.log(fmt.Sprintf("failed to re-initialize termbox: %s", err))
+		}
+		termbox.SetInputMode(inputMode())
+		termbox.SetOutputMode(theme.outputMode())
+		close(resume)
+	}()
+	return run()
+}
+
+// Wait while termbox is suspended
+func (s *Selector) waitResume() {
+	select {
+	case resume := <-s.suspended:
+		<-resume
+	default:
+	}
+}
+
+// Get x position of header click which finished choosing with CmdJump
+func (s *Selector) ClickedX() int {
+	return s.clickedX
+}
+
 // Pre handle keyPress event from App
 func (s *Selector) keyPress(evt termbox.Event) {
 	if len(s.guard) > 0 {
@@ -84,12 +346,31 @@ func (s *Selector) resize(width, height int) {
 	}
 }
 
+// Run function on choosing goroutine, which waits until choosing starts.
+// Returns false if stopped before the function is run
+func (s *Selector) Update(update func(), stop chan struct{}) bool {
+	select {
+	case s.onUpdate <- update:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
 // Change row offset
 func (s *Selector) SetOffset(offset int) *Selector {
 	s.offset = offset
 	return s
 }
 
+// Get number of list rows per page, last row is used for footer
+func (s *Selector) rows() int {
+	if rows := s.height - s.offset - 1; rows > 0 {
+		return rows
+	}
+	return 1
+}
+
 // Choose item from selectable list
 func (s *Selector) Choose(list Selectable) (int, error) {
 	s.guard <- struct{}{}
@@ -99,6 +380,7 @@ func (s *Selector) Choose(list Selectable) (int, error) {
 	}()
 
 	// start select
+	s.marked = nil
 	selected := make(chan int, 1)
 	errChan := make(chan error, 1)
 	go s.doSelect(list, selected, errChan)
@@ -108,6 +390,11 @@ func (s *Selector) Choose(list Selectable) (int, error) {
 
 func (s *Selector) doSelect(list Selectable, selected chan int, errChan chan error) {
 	state := NewSelectorState(list)
+	s.pending = nil
+	if s.initialView != nil {
+		s.restoreView(state, *s.initialView)
+		s.initialView = nil
+	}
 	s.display(state)
 
 	for {
@@ -116,80 +403,323 @@ func (s *Selector) doSelect(list Selectable, selected chan int, errChan chan err
 		// Handle resize event
 		case <-s.onResize:
 			s.display(state)
+			if state.overlay != "" {
+				s.displayOverlay(state)
+			}
+
+		// Handle update from other goroutine
+		case update := <-s.onUpdate:
+			s.mutex.Lock()
+			update()
+			termbox.Flush()
+			s.mutex.Unlock()
 
 		// Handle key event
 		case evt := <-s.onKeyPress:
 			logger.log("Handle keypress")
 			s.mutex.Lock()
-			switch {
-
-			// Pressed Ctrl+C or Esc
-			case evt.Key == termbox.KeyCtrlC || evt.Key == termbox.KeyEsc:
-				selected <- 0
-				errChan <- fmt.Errorf("interrupted")
-
-			// Pressed Arrow-Down key
-			case evt.Key == termbox.KeyArrowDown:
-				old, updated, paging := state.DownCursor(1)
-				logger.log("Down cursor")
-				s.inactive(old)
-				s.active(updated)
-				if paging {
-					s.display(state)
-				}
-				termbox.Flush()
-
-			// Pressed Arrow-Up key
-			case evt.Key == termbox.KeyArrowUp:
-				old, updated, paging := state.UpCursor(1)
-				logger.log("Up cursor")
-				s.inactive(old)
-				if paging {
-					if updated == -1 {
-						s.display(state)
-						state.pointer = state.listSize - 1
-						s.active(state.pointer)
-					} else {
-						state.pointer = s.height - s.offset - 1
-						s.active(state.pointer)
-						s.display(state)
-					}
-				} else {
-					s.active(updated)
+			// Any key closes overlay
+			if state.overlay != "" {
+				state.overlay = ""
+				s.display(state)
+				s.mutex.Unlock()
+				continue
+			}
+			if evt.Type == termbox.EventMouse {
+				if s.handleMouse(state, evt, selected, errChan) {
+					s.mutex.Unlock()
+					return
 				}
-				termbox.Flush()
-
-			// Pressed Enter key
-			case evt.Key == termbox.KeyEnter:
-				logger.log("Press Enter")
-				index, err := s.getFilteredIndex(state)
-				selected <- index
-				errChan <- err
+				s.mutex.Unlock()
+				continue
+			}
+			command, ok := s.resolveKey(state, evt)
+			if ok && s.runCommand(state, command, selected, errChan) {
 				s.mutex.Unlock()
 				return
+			}
+			s.mutex.Unlock()
+		}
+	}
+}
 
-			// Pressed Backspace
-			case s.enableFilter && evt.Key == termbox.KeyBackspace2:
-				logger.log("Press Backspace")
-				if update := state.popFilter(); update {
-					s.display(state)
-				}
+// Restore filter and move cursor to the item
+func (s *Selector) restoreView(state *SelectorState, view ListView) {
+	if s.enableFilter {
+		state.filters = []rune(view.Filter)
+	}
+	filtered, indexMap := s.filterList(state)
+	state.cursor = view.Cursor
+	if indexMap != nil {
+		state.cursor = 0
+		for i := range filtered {
+			if indexMap[i] == view.Cursor {
+				state.cursor = i
+				break
+			}
+		}
+	}
+	// Show restored cursor around the middle of viewport
+	state.top = state.cursor - s.rows()/2
+}
 
-			// Other character key
-			case s.enableFilter && evt.Ch > 0:
-				logger.log("Press " + string(evt.Ch))
-				state.addFilter(evt.Ch)
-				s.display(state)
+// Save cursor item index and filter, which is called before sending choosing result
+func (s *Selector) saveView(state *SelectorState) {
+	index, err := s.getFilteredIndex(state)
+	if err != nil {
+		index = 0
+	}
+	s.lastView = ListView{Cursor: index, Filter: string(state.filters)}
+}
+
+// Handle filter input and resolve key event into command
+func (s *Selector) resolveKey(state *SelectorState, evt termbox.Event) (Command, bool) {
+	stroke := strokeOf(evt)
+	printable := stroke.Ch != 0 && !stroke.Alt
+
+	// Row number input mode takes digits
+	if state.jump != nil {
+		switch {
+		case stroke.Key == termbox.KeyEnter:
+			if number, err := strconv.Atoi(string(state.jump)); err == nil {
+				state.Jump(number)
 			}
-			s.mutex.Unlock()
+			state.jump = nil
+			s.status.Clear()
+			s.display(state)
+		case stroke.Key == termbox.KeyBackspace && len(state.jump) > 0:
+			state.jump = state.jump[0 : len(state.jump)-1]
+			s.displayJump(state)
+		case stroke.Ch >= '0' && stroke.Ch <= '9' && !stroke.Alt:
+			state.jump = append(state.jump, stroke.Ch)
+			s.displayJump(state)
+		default:
+			// Other keys cancel jumping
+			state.jump = nil
+			s.status.Clear()
+			s.display(state)
+		}
+		return "", false
+	}
+
+	// Filter input mode takes text input keys
+	if state.filtering {
+		switch {
+		case stroke.Key == termbox.KeyEsc || stroke.Key == termbox.KeyCtrlC:
+			state.filtering = false
+			state.filters = []rune{}
+			s.display(state)
+			return "", false
+		case stroke.Key == termbox.KeyEnter:
+			state.filtering = false
+			s.display(state)
+			return "", false
+		case stroke.Key == termbox.KeyBackspace:
+			state.popFilter()
+			s.display(state)
+			return "", false
+		case printable:
+			state.addFilter(stroke.Ch)
+			s.display(state)
+			return "", false
 		}
+	} else if s.enableFilter && keymap.instantFilter && stroke.Key == termbox.KeyBackspace {
+		logger.log("Press Backspace")
+		if update := state.popFilter(); update {
+			s.display(state)
+		}
+		return "", false
 	}
+
+	s.pending = append(s.pending, stroke)
+	command, partial := keymap.resolve(s.pending, s.isEnabled)
+	if command == "" && !partial && len(s.pending) > 1 {
+		// Sequence is broken, try last stroke only
+		s.pending = []KeyStroke{stroke}
+		command, partial = keymap.resolve(s.pending, s.isEnabled)
+	}
+	if partial {
+		return "", false
+	}
+	s.pending = nil
+	if command != "" {
+		return command, true
+	}
+
+	// Digit starts row number input unless it's input to filter
+	if printable && stroke.Ch >= '1' && stroke.Ch <= '9' && !(s.enableFilter && keymap.instantFilter) {
+		state.jump = []rune{stroke.Ch}
+		s.displayJump(state)
+		return "", false
+	}
+
+	// Other character key
+	if printable && s.enableFilter && keymap.instantFilter {
+		logger.log("Press " + string(stroke.Ch))
+		state.addFilter(stroke.Ch)
+		s.display(state)
+	}
+	return "", false
+}
+
+// Run command, returns true when choosing is finished
+func (s *Selector) runCommand(state *SelectorState, command Command, selected chan int, errChan chan error) bool {
+	switch command {
+
+	// Cancel choosing, or clear filter first if filter is not input instantly
+	case CmdBack:
+		if !keymap.instantFilter && len(state.filters) > 0 {
+			state.filters = []rune{}
+			s.display(state)
+			return false
+		}
+		s.saveView(state)
+		selected <- 0
+		errChan <- ErrInterrupted
+		return true
+
+	// Choose item
+	case CmdSelect:
+		logger.log("Press Enter")
+		index, err := s.getFilteredIndex(state)
+		s.marked = state.markedIndexes()
+		s.saveView(state)
+		selected <- index
+		errChan <- err
+		return true
+
+	case CmdDown:
+		logger.log("Down cursor")
+		s.move(state, state.DownCursor)
+
+	case CmdUp:
+		logger.log("Up cursor")
+		s.move(state, state.UpCursor)
+
+	case CmdPageDown:
+		s.move(state, state.PageDown)
+
+	case CmdPageUp:
+		s.move(state, state.PageUp)
+
+	case CmdHalfPageDown:
+		s.move(state, state.HalfPageDown)
+
+	case CmdHalfPageUp:
+		s.move(state, state.HalfPageUp)
+
+	case CmdTop:
+		s.move(state, state.Top)
+
+	case CmdBottom:
+		s.move(state, state.Bottom)
+
+	// Start row number input for jumping
+	case CmdGotoRow:
+		state.jump = []rune{}
+		s.displayJump(state)
+
+	// Show key bindings or last error details overlay
+	case CmdHelp, CmdErrorDetails:
+		state.overlay = command
+		s.displayOverlay(state)
+
+	// Start filter input mode
+	case CmdFilter:
+		state.filtering = true
+		s.display(state)
+
+	// Toggle mark and down cursor
+	case CmdMark:
+		logger.log("Press mark")
+		if index, err := s.getFilteredIndex(state); err == nil {
+			state.toggleMark(index)
+		}
+		s.move(state, state.DownCursor)
+
+	// Bound application command
+	default:
+		logger.log("Press bound command " + string(command))
+		index, err := s.getFilteredIndex(state)
+		if err != nil {
+			index = -1
+		}
+		s.marked = state.markedIndexes()
+		s.saveView(state)
+		selected <- index
+		errChan <- &KeyBindError{Command: command}
+		return true
+	}
+	return false
+}
+
+// Handle mouse event. Click moves cursor, double click chooses item and wheel scrolls list
+func (s *Selector) handleMouse(state *SelectorState, evt termbox.Event, selected chan int, errChan chan error) bool {
+	switch {
+	case evt.Key == termbox.MouseWheelUp:
+		s.move(state, func() {
+			state.Wheel(-wheelRows)
+		})
+		return false
+	case evt.Key == termbox.MouseWheelDown:
+		s.move(state, func() {
+			state.Wheel(wheelRows)
+		})
+		return false
+	case evt.Key != termbox.MouseLeft || evt.Mod&termbox.ModMotion != 0:
+		return false
+	}
+
+	// Header click is handled by App
+	if evt.MouseY == 0 {
+		if !s.isEnabled(CmdJump) {
+			return false
+		}
+		s.clickedX = evt.MouseX
+		return s.runCommand(state, CmdJump, selected, errChan)
+	}
+
+	row := evt.MouseY - s.offset
+	if row < 0 || row >= state.rows || state.top+row >= state.total {
+		return false
+	}
+	now := time.Now()
+	if row == state.row() && now.Sub(s.lastClick) < doubleClickInterval {
+		s.lastClick = time.Time{}
+		return s.runCommand(state, CmdSelect, selected, errChan)
+	}
+	s.lastClick = now
+	s.move(state, func() {
+		state.cursor = state.top + row
+	})
+	return false
+}
+
+// Move cursor by function, then repaint changed rows or whole list if viewport is scrolled
+func (s *Selector) move(state *SelectorState, moving func()) {
+	top, row := state.top, state.row()
+	moving()
+	if state.top != top {
+		s.display(state)
+		return
+	}
+	s.inactive(state, row)
+	s.active(state.row())
+	if s.enableFilter {
+		s.displayInfo(state)
+	}
+	termbox.Flush()
 }
 
 // Get selected item considering with filter query
 func (s *Selector) getFilteredIndex(state *SelectorState) (int, error) {
+	return s.getRowIndex(state, state.row())
+}
+
+// Get item index of display row considering with filter query
+func (s *Selector) getRowIndex(state *SelectorState, pointer int) (int, error) {
 	_, indexMap := s.filterList(state)
-	index := (state.page-1)*s.height + state.pointer
+	index := state.top + pointer
 
 	if indexMap == nil {
 		return index, nil
@@ -226,39 +756,40 @@ func (s *Selector) display(state *SelectorState) {
 	s.Clear()
 	// Get filtered list items
 	filtered, _ := s.filterList(state)
-	// Cauclaute max page
-	state.updatePage(int(math.Ceil(float64(len(filtered)) / float64(s.height))))
-	// Calcualte start and end index
-	start := (state.page - 1) * (s.height - s.offset)
-	end := start + (s.height - s.offset)
+	state.setView(len(filtered), s.rows())
+	// Calcualte start and end index of viewport
+	start := state.top
+	end := start + state.rows
 	if end > len(filtered) {
 		end = len(filtered)
 	}
 
-	// Slice list per page and write to term
-	displayList := filtered[start:end]
+	// Slice list in viewport and write to term
 	strFilter := string(state.filters)
-	state.listSize = 0
-	pointer := 0
-	for i, line := range displayList {
+	for i, line := range filtered[start:end] {
 		line.Write(i+s.offset, strFilter)
-		if state.pointer == i {
-			s.active(state.pointer)
-			pointer = state.pointer
+		s.markRow(state, i)
+		if state.row() == i {
+			s.active(i)
 		}
-		state.listSize++
 	}
-	state.pointer = pointer
+	s.displayFooter()
 	if s.enableFilter {
-		s.displayInfo(len(filtered), state.page, state.maxPage)
-		s.status.Message(fmt.Sprintf("Filter query> %s", string(state.filters)), 0)
+		s.displayInfo(state)
+		if keymap.instantFilter || state.filtering || len(state.filters) > 0 {
+			s.status.Message(fmt.Sprintf("Filter query> %s", string(state.filters)), 0)
+		}
+	}
+	if state.jump != nil {
+		s.displayJump(state)
 	}
 	termbox.Flush()
 }
 
-// Display filtered total item amounts and page / maxPage
-func (s *Selector) displayInfo(listLen, page, maxPage int) {
-	info := []rune(fmt.Sprintf("(Total %d: %d of %d)", listLen, page, maxPage))
+// Display filtered total item amounts, cursor row and page / maxPage
+func (s *Selector) displayInfo(state *SelectorState) {
+	page, maxPage := state.pages()
+	info := []rune(fmt.Sprintf("(Total %d: row %d, %d of %d)", state.total, state.cursor+1, page, maxPage))
 	x := s.width - len(info)
 
 	// FIXME: This is fragile...
@@ -271,6 +802,26 @@ func (s *Selector) displayInfo(listLen, page, maxPage int) {
 	}
 }
 
+// Display row number input on status row
+func (s *Selector) displayJump(state *SelectorState) {
+	s.status.Message(fmt.Sprintf("Go to row (1-%d)> %s", state.total, string(state.jump)), 0)
+}
+
+// Display footer hint of main keys on last row
+func (s *Selector) displayFooter() {
+	hints := []Command{}
+	for _, command := range []Command{CmdSelect, CmdBack, CmdFilter, CmdMark, CmdHelp} {
+		if s.isEnabled(command) {
+			hints = append(hints, command)
+		}
+	}
+	x := 0
+	for _, r := range keymap.Hint(hints...) {
+		termbox.SetCell(x, s.height-1, r, theme.Muted.Fg, theme.Muted.Bg)
+		x += runewidth.RuneWidth(r)
+	}
+}
+
 // Clear the termbox buffer only selector drawable indexes
 func (s *Selector) Clear() {
 	for i := s.offset; i < s.height; i++ {
@@ -280,24 +831,34 @@ func (s *Selector) Clear() {
 	}
 }
 
-// Inactive cursor
-func (s *Selector) inactive(pointer int) {
-	index := (pointer + s.offset) * s.width
-	cb := termbox.CellBuffer()
+// Inactive cursor, row is written again to restore item styles
+func (s *Selector) inactive(state *SelectorState, pointer int) {
+	y := pointer + s.offset
 	for i := 0; i < s.width; i++ {
-		cell := cb[index+i]
-		cell.Bg = termbox.ColorDefault
-		cb[index+i] = cell
+		termbox.SetCell(i, y, rune(' '), termbox.ColorDefault, termbox.ColorDefault)
+	}
+	filtered, _ := s.filterList(state)
+	index := state.top + pointer
+	if pointer < 0 || index < 0 || index >= len(filtered) {
+		return
 	}
+	filtered[index].Write(y, string(state.filters))
+	s.markRow(state, pointer)
+}
+
+// Paint row with mark style if the item is marked
+func (s *Selector) markRow(state *SelectorState, pointer int) {
+	if len(state.marked) == 0 {
+		return
+	}
+	index, err := s.getRowIndex(state, pointer)
+	if err != nil || !state.isMarked(index) {
+		return
+	}
+	styleRow(pointer+s.offset, s.width, theme.Mark)
 }
 
 // Activate cursor
 func (s *Selector) active(pointer int) {
-	index := (pointer + s.offset) * s.width
-	cb := termbox.CellBuffer()
-	for i := 0; i < s.width; i++ {
-		cell := cb[index+i]
-		cell.Bg = termbox.ColorMagenta
-		cb[index+i] = cell
-	}
+	styleRow(pointer+s.offset, s.width, theme.Cursor)
 }
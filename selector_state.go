@@ -1,82 +1,187 @@
 package main
 
+import (
+	"sort"
+)
+
 // Store selecting paramter struct
 type SelectorState struct {
 
-	// Cussor pointer row
-	pointer int
+	// Absolute cursor index in filtered list
+	cursor int
 
-	// Displaying page number
-	page int
+	// First filtered index of viewport
+	top int
 
-	// Displaying list amount
-	listSize int
+	// Viewport row amount
+	rows int
 
-	// Max page number
-	maxPage int
+	// Filtered list amount
+	total int
 
 	// Filtering query
 	filters []rune
 
+	// Filter input mode, printable keys are input to filter
+	filtering bool
+
+	// Row number input for jumping, nil when not jumping
+	jump []rune
+
+	// Displayed overlay, CmdHelp or CmdErrorDetails. Empty when no overlay is displayed
+	overlay Command
+
 	// List items
 	items Selectable
+
+	// Marked item indexes
+	marked map[int]struct{}
 }
 
 // Make new state pointer struct
 func NewSelectorState(list Selectable) *SelectorState {
 	return &SelectorState{
-		page:    1,
+		rows:    1,
 		filters: []rune{},
 		items:   list,
+		marked:  make(map[int]struct{}),
+	}
+}
+
+// Update list amount and viewport size, then clamp cursor and keep it in viewport
+func (s *SelectorState) setView(total, rows int) {
+	s.total = total
+	if rows < 1 {
+		rows = 1
+	}
+	s.rows = rows
+	s.clamp()
+}
+
+// Clamp cursor into list and scroll viewport to show cursor
+func (s *SelectorState) clamp() {
+	if s.cursor >= s.total {
+		s.cursor = s.total - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+	if s.cursor < s.top {
+		s.top = s.cursor
+	} else if s.cursor >= s.top+s.rows {
+		s.top = s.cursor - s.rows + 1
 	}
+	// Avoid blank rows at the end when list is longer than viewport
+	if last := s.total - s.rows; s.top > last {
+		s.top = last
+	}
+	if s.top < 0 {
+		s.top = 0
+	}
+}
+
+// Get cursor row in viewport
+func (s *SelectorState) row() int {
+	return s.cursor - s.top
+}
+
+// Get current page and max page for displaying
+func (s *SelectorState) pages() (int, int) {
+	maxPage := (s.total + s.rows - 1) / s.rows
+	page := s.cursor/s.rows + 1
+	if maxPage == 0 {
+		page = 0
+	}
+	return page, maxPage
 }
 
-// Down cursor
-func (s *SelectorState) DownCursor(step int) (old, updated int, paging bool) {
-	old = s.pointer
-	if s.pointer+step < s.listSize {
-		s.pointer += step
-	} else if s.page < s.maxPage {
-		s.pointer = 0
-		s.page++
-		paging = true
-	} else { // go to first page from last
-		s.pointer = 0
-		s.page = 1
-		paging = true
-	}
-	updated = s.pointer
-	return
-}
-
-// Up cursor
-func (s *SelectorState) UpCursor(step int) (old, updated int, paging bool) {
-	old = s.pointer
-	if s.pointer-step >= 0 {
-		s.pointer -= step
-	} else if s.page == 1 { // go to last page from first
-		// lazy update pointer
-		s.pointer = -1
-		s.page = s.maxPage
-		paging = true
+// Down cursor, go to first item from last
+func (s *SelectorState) DownCursor() {
+	if s.cursor+1 < s.total {
+		s.cursor++
 	} else {
-		// lazy update pointer
-		s.pointer = -2
-		s.page--
-		paging = true
+		s.cursor = 0
 	}
-	updated = s.pointer
-	return
+	s.clamp()
 }
 
-// Update page state
-func (s *SelectorState) updatePage(maxPage int) {
-	if s.page > maxPage {
-		s.page = 1
-	} else if s.page < 1 {
-		s.page = maxPage
+// Up cursor, go to last item from first
+func (s *SelectorState) UpCursor() {
+	if s.cursor > 0 {
+		s.cursor--
+	} else {
+		s.cursor = s.total - 1
 	}
-	s.maxPage = maxPage
+	s.clamp()
+}
+
+// Scroll viewport and cursor by step rows, cursor stops at both ends
+func (s *SelectorState) scroll(step int) {
+	s.cursor += step
+	s.top += step
+	s.clamp()
+}
+
+// Scroll viewport by step rows and keep cursor in viewport. Cursor moves to the end
+// when viewport can't be scrolled further, so wheel reaches both ends of list
+func (s *SelectorState) Wheel(step int) {
+	top := s.top + step
+	if last := s.total - s.rows; top > last {
+		top = last
+	}
+	if top < 0 {
+		top = 0
+	}
+	switch {
+	case top == s.top && step > 0:
+		s.cursor = s.total - 1
+	case top == s.top && step < 0:
+		s.cursor = 0
+	case s.cursor < top:
+		s.cursor = top
+	case s.cursor >= top+s.rows:
+		s.cursor = top + s.rows - 1
+	}
+	s.top = top
+	s.clamp()
+}
+
+// Move to next page
+func (s *SelectorState) PageDown() {
+	s.scroll(s.rows)
+}
+
+// Move to previous page
+func (s *SelectorState) PageUp() {
+	s.scroll(-s.rows)
+}
+
+// Move half page down
+func (s *SelectorState) HalfPageDown() {
+	s.scroll((s.rows + 1) / 2)
+}
+
+// Move half page up
+func (s *SelectorState) HalfPageUp() {
+	s.scroll(-(s.rows + 1) / 2)
+}
+
+// Move to first item
+func (s *SelectorState) Top() {
+	s.cursor = 0
+	s.clamp()
+}
+
+// Move to last item
+func (s *SelectorState) Bottom() {
+	s.cursor = s.total - 1
+	s.clamp()
+}
+
+// Move to row number which starts from 1
+func (s *SelectorState) Jump(number int) {
+	s.cursor = number - 1
+	s.clamp()
 }
 
 // Pop filter word
@@ -92,3 +197,28 @@ func (s *SelectorState) popFilter() bool {
 func (s *SelectorState) addFilter(f rune) {
 	s.filters = append(s.filters, f)
 }
+
+// Toggle mark of item index
+func (s *SelectorState) toggleMark(index int) {
+	if _, ok := s.marked[index]; ok {
+		delete(s.marked, index)
+	} else {
+		s.marked[index] = struct{}{}
+	}
+}
+
+// Check item index is marked
+func (s *SelectorState) isMarked(index int) bool {
+	_, ok := s.marked[index]
+	return ok
+}
+
+// Get sorted marked item indexes
+func (s *SelectorState) markedIndexes() []int {
+	indexes := []int{}
+	for index := range s.marked {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
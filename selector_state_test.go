@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSelectorStateNavigation(t *testing.T) {
+	state := NewSelectorState(Selectable{})
+	// 25 items in 10 rows viewport, last page has 5 items
+	state.setView(25, 10)
+
+	assert := func(name string, cursor, top int) {
+		t.Helper()
+		if state.cursor != cursor || state.top != top {
+			t.Errorf("%s: expected cursor %d top %d, actual cursor %d top %d", name, cursor, top, state.cursor, state.top)
+		}
+	}
+
+	state.UpCursor()
+	assert("up from first wraps to last", 24, 15)
+	state.DownCursor()
+	assert("down from last wraps to first", 0, 0)
+
+	for i := 0; i < 10; i++ {
+		state.DownCursor()
+	}
+	assert("down scrolls by row", 10, 1)
+
+	state.PageDown()
+	assert("page down", 20, 11)
+	state.PageDown()
+	assert("page down stops at last", 24, 15)
+	state.PageUp()
+	assert("page up", 14, 5)
+	state.HalfPageUp()
+	assert("half page up", 9, 0)
+	state.HalfPageDown()
+	assert("half page down", 14, 5)
+
+	state.Bottom()
+	assert("bottom", 24, 15)
+	if page, maxPage := state.pages(); page != 3 || maxPage != 3 {
+		t.Errorf("expected page 3 of 3, actual %d of %d", page, maxPage)
+	}
+	state.Top()
+	assert("top", 0, 0)
+
+	state.Jump(18)
+	assert("jump", 17, 8)
+	state.Jump(100)
+	assert("jump over last", 24, 15)
+	state.Jump(0)
+	assert("jump before first", 0, 0)
+
+	state.Wheel(3)
+	assert("wheel down scrolls viewport and keeps cursor", 3, 3)
+	for i := 0; i < 4; i++ {
+		state.Wheel(3)
+	}
+	assert("wheel down clamps cursor into viewport", 15, 15)
+	state.Wheel(3)
+	assert("wheel down at the end moves cursor to last", 24, 15)
+	state.Wheel(-3)
+	assert("wheel up clamps cursor into viewport", 21, 12)
+	state.Top()
+	state.Wheel(-3)
+	assert("wheel up at the top", 0, 0)
+
+	// Filtered list becomes shorter than viewport
+	state.Bottom()
+	state.setView(3, 10)
+	assert("shrink", 2, 0)
+	state.setView(0, 10)
+	assert("empty", 0, 0)
+	state.DownCursor()
+	state.UpCursor()
+	assert("move in empty", 0, 0)
+}
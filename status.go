@@ -1,16 +1,19 @@
 package main
 
 import (
+	"fmt"
 	"github.com/nsf/termbox-go"
 	"time"
 )
 
 type Status struct {
 	row     int
-	bgColor termbox.Attribute
-	fgColor termbox.Attribute
+	style   Style
 	message string
 
+	// Details of last error, which are shown by error-details command
+	details []string
+
 	width  int
 	height int
 }
@@ -18,11 +21,9 @@ type Status struct {
 func NewStatus(row int) *Status {
 	width, height := termbox.Size()
 	return &Status{
-		row:     row,
-		bgColor: termbox.ColorDefault,
-		fgColor: termbox.ColorDefault,
-		width:   width,
-		height:  height,
+		row:    row,
+		width:  width,
+		height: height,
 	}
 }
 
@@ -34,40 +35,51 @@ func (s *Status) Clear() {
 
 func (s *Status) Message(message string, delay int64) chan struct{} {
 	s.message = message
-	s.bgColor = termbox.ColorDefault
-	s.fgColor = termbox.ColorWhite
+	s.style = theme.Message
 	return s.display([]rune(message), delay)
 }
 
 func (s *Status) Info(message string, delay int64) chan struct{} {
 	s.message = message
-	s.bgColor = termbox.ColorCyan
-	s.fgColor = termbox.ColorBlack
+	s.style = theme.Info
 	return s.display([]rune(message), delay)
 }
 
 func (s *Status) Warn(message string, delay int64) chan struct{} {
 	s.message = message
-	s.bgColor = termbox.ColorYellow
-	s.fgColor = termbox.ColorBlack
+	s.style = theme.Warn
 	return s.display([]rune(message), delay)
 }
 
 func (s *Status) Error(message string, delay int64) chan struct{} {
 	s.message = message
-	s.bgColor = termbox.ColorRed
-	s.fgColor = termbox.ColorWhite
+	s.style = theme.Error
 	return s.display([]rune(message), delay)
 }
 
+// Show error message with error code, and keep details of the error to show on demand
+func (s *Status) Failure(message string, err error, delay int64) chan struct{} {
+	s.details = errorDetails(message, err)
+	line := fmt.Sprintf("%s: %s", message, errorCode(err))
+	if hint := keymap.Hint(CmdErrorDetails); hint != "" {
+		line += " (" + hint + ")"
+	}
+	return s.Error(line, delay)
+}
+
+// Get details of last error
+func (s *Status) Details() []string {
+	return s.details
+}
+
 func (s *Status) display(message []rune, delay int64) chan struct{} {
 	s.Clear()
 	w, _ := termbox.Size()
 	for i, r := range message {
-		termbox.SetCell(i, s.row, r, s.fgColor, s.bgColor)
+		termbox.SetCell(i, s.row, r, s.style.Fg, s.style.Bg)
 	}
 	for i := len(message); i < w; i++ {
-		termbox.SetCell(i, s.row, rune(' '), termbox.ColorDefault, s.bgColor)
+		termbox.SetCell(i, s.row, rune(' '), s.style.Fg, s.style.Bg)
 	}
 	termbox.Flush()
 
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Storage classes which objects can transition to
+var transitionStorageClasses = []string{
+	s3.StorageClassStandard,
+	s3.StorageClassStandardIa,
+	s3.StorageClassOnezoneIa,
+	s3.StorageClassIntelligentTiering,
+	s3.StorageClassGlacierIr,
+	s3.StorageClassGlacier,
+	s3.StorageClassDeepArchive,
+}
+
+// Short storage class names for object list column
+var shortStorageClasses = map[string]string{
+	s3.StorageClassStandard:           "STD",
+	s3.StorageClassReducedRedundancy:  "RRS",
+	s3.StorageClassStandardIa:         "IA",
+	s3.StorageClassOnezoneIa:          "OZ-IA",
+	s3.StorageClassIntelligentTiering: "INT",
+	s3.StorageClassGlacierIr:          "GIR",
+	s3.StorageClassGlacier:            "GLACIER",
+	s3.StorageClassDeepArchive:        "DEEP",
+	s3.StorageClassOutposts:           "OUTPOSTS",
+	s3.StorageClassExpressOnezone:     "EXPRESS",
+}
+
+// Restore header pattern, e.g. ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+var restorePattern = regexp.MustCompile(`ongoing-request="(true|false)"(?:,\s*expiry-date="([^"]+)")?`)
+
+// Error which indicates archived object is not restored
+type archivedError struct {
+	storageClass string
+}
+
+// error::Error implementation
+func (a *archivedError) Error() string {
+	return fmt.Sprintf("Object is archived in %s, restore it first", a.storageClass)
+}
+
+// Get short storage class name
+func shortStorageClass(storageClass string) string {
+	if storageClass == "" {
+		return ""
+	}
+	if short, ok := shortStorageClasses[storageClass]; ok {
+		return short
+	}
+	return storageClass
+}
+
+// Check storage class needs restore before reading
+func isArchived(storageClass string) bool {
+	return storageClass == s3.StorageClassGlacier || storageClass == s3.StorageClassDeepArchive
+}
+
+// Parse restore header and returns ongoing flag and readable status
+func restoreStatus(restore *string) (bool, string) {
+	if restore == nil || *restore == "" {
+		return false, "-"
+	}
+	matches := restorePattern.FindStringSubmatch(*restore)
+	if matches == nil {
+		return false, *restore
+	}
+	if matches[1] == "true" {
+		return true, "In progress"
+	}
+	if matches[2] == "" {
+		return false, "Restored"
+	}
+	expiry, err := time.Parse(time.RFC1123, matches[2])
+	if err != nil {
+		return false, fmt.Sprintf("Restored, expires at %s", matches[2])
+	}
+	return false, fmt.Sprintf("Restored, expires at %s", localTime(expiry))
+}
+
+// Check object body is readable, archived object needs to be restored
+func isReadable(head *s3.HeadObjectOutput) bool {
+	if head.ArchiveStatus != nil {
+		return false
+	}
+	if !isArchived(aws.StringValue(head.StorageClass)) {
+		return true
+	}
+	ongoing, status := restoreStatus(head.Restore)
+	return !ongoing && status != "-"
+}
+
+// Transition object to another storage class by self copy which keeps ACL grants
+func transitionObject(service *s3.S3, bucket, key string, head *s3.HeadObjectOutput, grants Grants, storageClass string) error {
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		CopySource:        aws.String(copySource(bucket, key, "")),
+		MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
+		StorageClass:      aws.String(storageClass),
+		CopySourceIfMatch: head.ETag,
+		GrantFullControl:  grants.FullControl,
+		GrantRead:         grants.Read,
+		GrantReadACP:      grants.ReadACP,
+		GrantWriteACP:     grants.WriteACP,
+	}
+	if head.ServerSideEncryption != nil {
+		input.ServerSideEncryption = head.ServerSideEncryption
+		input.SSEKMSKeyId = head.SSEKMSKeyId
+	}
+	_, err := service.CopyObject(input)
+	return err
+}
+
+// Start restoring archived object
+func restoreObject(service *s3.S3, bucket, key, versionId, tier string, days int64) error {
+	input := &s3.RestoreObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		RestoreRequest: &s3.RestoreRequest{
+			Days: aws.Int64(days),
+			GlacierJobParameters: &s3.GlacierJobParameters{
+				Tier: aws.String(tier),
+			},
+		},
+	}
+	if versionId != "" {
+		input = input.SetVersionId(versionId)
+	}
+	_, err := service.RestoreObject(input)
+	return err
+}
+
+// Choose storage class by form
+func chooseStorageClass(current string, selector *Selector, status *Status) (string, bool, error) {
+	form := NewForm("Change storage class", selector, status)
+	field := form.Field("Storage Class", current, transitionStorageClasses...)
+	if current == "" {
+		field.placeholder = "(choose)"
+	}
+	saved, err := form.Do()
+	if err != nil || !saved || !field.changed || field.value == current {
+		return "", false, err
+	}
+	return field.value, true, nil
+}
+
+// Input restore tier and days by form
+func editRestore(selector *Selector, status *Status) (string, int64, bool, error) {
+	form := NewForm("Restore archived object", selector, status)
+	form.Field("Tier", s3.TierStandard, s3.TierExpedited, s3.TierStandard, s3.TierBulk)
+	form.Field("Days", "7")
+	for {
+		saved, err := form.Do()
+		if err != nil || !saved {
+			return "", 0, false, err
+		}
+		days, err := strconv.ParseInt(form.Value("Days"), 10, 64)
+		if err != nil || days < 1 {
+			<-status.Error("Days must be a positive number", 1)
+			continue
+		}
+		return form.Value("Tier"), days, true, nil
+	}
+}
+
+// Transition objects to another storage class with confirmation
+func transitionObjects(service *s3.S3, bucket string, keys []string, selector *Selector, status *Status) error {
+	storageClass, ok, err := chooseStorageClass("", selector, status)
+	if err != nil || !ok {
+		return err
+	}
+
+	type target struct {
+		head       *s3.HeadObjectOutput
+		grants     Grants
+		unreadable bool
+	}
+	targets := make([]*target, len(keys))
+	failed, err := parallel(len(keys), func(i int) error {
+		head, err := service.HeadObject(&s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(keys[i]),
+		})
+		if err != nil {
+			return err
+		}
+		if aws.StringValue(head.StorageClass) == storageClass {
+			return nil
+		}
+		// Self copy resets ACL, so grants are copied if readable
+		grants, err := fetchGrants(service, bucket, keys[i], "")
+		if err != nil {
+			logger.log(fmt.Sprintf("Failed to get ACL of %s: %s", keys[i], err))
+		}
+		targets[i] = &target{head: head, grants: grants, unreadable: err != nil}
+		return nil
+	}, func(done int) {
+		status.Message(fmt.Sprintf("Inspecting objects %d / %d ...", done, len(keys)), 0)
+	})
+	if err != nil {
+		<-status.Failure(fmt.Sprintf("Failed to get metadata of %s", keys[failed]), err, 2)
+		return nil
+	}
+	indexes := []int{}
+	unreadable := 0
+	for i, t := range targets {
+		if t == nil {
+			continue
+		}
+		indexes = append(indexes, i)
+		if t.unreadable {
+			unreadable++
+		}
+	}
+	if len(indexes) == 0 {
+		<-status.Warn(fmt.Sprintf("All objects are already %s", storageClass), 1)
+		return nil
+	}
+
+	message := fmt.Sprintf("Transition %d objects to %s?", len(indexes), storageClass)
+	if unreadable > 0 {
+		message = fmt.Sprintf("ACL of %d objects is not readable and will be reset to private. %s", unreadable, message)
+	}
+	if !selector.Confirm(message) {
+		return nil
+	}
+
+	failed, err = parallel(len(indexes), func(i int) error {
+		t := targets[indexes[i]]
+		return transitionObject(service, bucket, keys[indexes[i]], t.head, t.grants, storageClass)
+	}, func(done int) {
+		status.Info(fmt.Sprintf("Transitioning %d / %d ...", done, len(indexes)), 0)
+	})
+	if err != nil {
+		<-status.Failure(fmt.Sprintf("Failed to transition %s", keys[indexes[failed]]), err, 2)
+		return nil
+	}
+	<-status.Info(fmt.Sprintf("Transitioned %d objects to %s", len(indexes), storageClass), 1)
+	return nil
+}
+
+// Start restoring objects, not archived or already restoring objects are skipped
+func restoreObjects(service *s3.S3, bucket string, keys []string, selector *Selector, status *Status) error {
+	tier, days, ok, err := editRestore(selector, status)
+	if err != nil || !ok {
+		return err
+	}
+
+	var started int32
+	failed, err := parallel(len(keys), func(i int) error {
+		err := restoreObject(service, bucket, keys[i], "", tier, days)
+		switch errorCode(err) {
+		case "InvalidObjectState", "RestoreAlreadyInProgress":
+			return nil
+		case "":
+			atomic.AddInt32(&started, 1)
+		}
+		return err
+	}, func(done int) {
+		status.Info(fmt.Sprintf("Requesting restore %d / %d ...", done, len(keys)), 0)
+	})
+	if err != nil {
+		<-status.Failure(fmt.Sprintf("Failed to restore %s", keys[failed]), err, 2)
+		return nil
+	}
+	<-status.Info(fmt.Sprintf("Started restoring %d objects", started), 1)
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestRestoreStatus(t *testing.T) {
+	if ongoing, status := restoreStatus(nil); ongoing || status != "-" {
+		t.Errorf("expected not restored, actual %t, %s", ongoing, status)
+	}
+	if ongoing, status := restoreStatus(aws.String(`ongoing-request="true"`)); !ongoing || status != "In progress" {
+		t.Errorf("expected in progress, actual %t, %s", ongoing, status)
+	}
+	ongoing, status := restoreStatus(aws.String(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`))
+	expiry := time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC)
+	if ongoing || status != "Restored, expires at "+localTime(expiry) {
+		t.Errorf("expected restored, actual %t, %s", ongoing, status)
+	}
+}
+
+func TestIsReadable(t *testing.T) {
+	if !isReadable(&s3.HeadObjectOutput{}) {
+		t.Errorf("standard object expected readable")
+	}
+	if isReadable(&s3.HeadObjectOutput{StorageClass: aws.String(s3.StorageClassGlacier)}) {
+		t.Errorf("archived object expected not readable")
+	}
+	restored := &s3.HeadObjectOutput{
+		StorageClass: aws.String(s3.StorageClassDeepArchive),
+		Restore:      aws.String(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`),
+	}
+	if !isReadable(restored) {
+		t.Errorf("restored object expected readable")
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Max tag amount per object
+const maxTags = 10
+
+// Object tags struct
+type Tags map[string]string
+
+// Create tags from S3 tag set
+func tagsFromSet(tagSet []*s3.Tag) Tags {
+	t := Tags{}
+	for _, tag := range tagSet {
+		t[*tag.Key] = *tag.Value
+	}
+	return t
+}
+
+// Get sorted tag keys
+func (t Tags) keys() []string {
+	keys := []string{}
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Transform to S3 tag set
+func (t Tags) tagSet() []*s3.Tag {
+	tagSet := []*s3.Tag{}
+	for _, k := range t.keys() {
+		tagSet = append(tagSet, &s3.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(t[k]),
+		})
+	}
+	return tagSet
+}
+
+// Transform to URL query format for x-amz-tagging header
+func (t Tags) query() string {
+	values := url.Values{}
+	for k, v := range t {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// Tag changes, nil value means removing
+type TagChange map[string]*string
+
+// Get change lines for preview
+func (c TagChange) lines() []string {
+	keys := []string{}
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	lines := []string{}
+	for _, k := range keys {
+		if c[k] == nil {
+			lines = append(lines, fmt.Sprintf("  - %s", k))
+		} else {
+			lines = append(lines, fmt.Sprintf("  + %s = %s", k, *c[k]))
+		}
+	}
+	return lines
+}
+
+// Apply changes and returns new tags and changed flag
+func (t Tags) Apply(change TagChange) (Tags, bool) {
+	updated := Tags{}
+	for k, v := range t {
+		updated[k] = v
+	}
+	changed := false
+	for k, v := range change {
+		old, exists := t[k]
+		switch {
+		case v == nil && exists:
+			delete(updated, k)
+			changed = true
+		case v == nil:
+			continue
+		case !exists || old != *v:
+			updated[k] = *v
+			changed = true
+		}
+	}
+	return updated, changed
+}
+
+// Fetch object tags
+func fetchTags(service *s3.S3, bucket, key, versionId string) (Tags, error) {
+	input := &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionId != "" {
+		input = input.SetVersionId(versionId)
+	}
+	result, err := service.GetObjectTagging(input)
+	if err != nil {
+		return nil, err
+	}
+	return tagsFromSet(result.TagSet), nil
+}
+
+// Put object tags, or delete tagging if tags are empty
+func putTags(service *s3.S3, bucket, key string, tags Tags) error {
+	if len(tags) > maxTags {
+		return fmt.Errorf("%s would have %d tags, limit is %d", key, len(tags), maxTags)
+	}
+	if len(tags) == 0 {
+		_, err := service.DeleteObjectTagging(&s3.DeleteObjectTaggingInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		return err
+	}
+	_, err := service.PutObjectTagging(&s3.PutObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Tagging: &s3.Tagging{
+			TagSet: tags.tagSet(),
+		},
+	})
+	return err
+}
+
+// Format tags for info panel
+func tagsInfo(tags Tags, err error) []string {
+	if err != nil {
+		return []string{fmt.Sprintf("%-24s: (%s)", "Tags", errorCode(err))}
+	}
+	if len(tags) == 0 {
+		return []string{fmt.Sprintf("%-24s: %s", "Tags", "-")}
+	}
+	infoList := []string{}
+	for _, k := range tags.keys() {
+		infoList = append(infoList, fmt.Sprintf("%-24s: %s = %s", "Tag", k, tags[k]))
+	}
+	return infoList
+}
+
+// Edit tags by form. If tags is nil, edit for multiple objects and only changed tags are applied
+func editTags(tags Tags, selector *Selector, status *Status) (TagChange, bool, error) {
+	form := NewForm("Edit tags (empty value is allowed, [-] removes tag)", selector, status).Extendable("").Removable()
+	for _, k := range tags.keys() {
+		form.Field(k, tags[k])
+	}
+
+	saved, err := form.Do()
+	if err != nil || !saved {
+		return nil, false, err
+	}
+
+	change := TagChange{}
+	for _, field := range form.fields {
+		if !field.changed {
+			continue
+		}
+		if field.removed {
+			change[field.label] = nil
+		} else {
+			change[field.label] = aws.String(field.value)
+		}
+	}
+	return change, len(change) > 0, nil
+}
+
+// Apply tag change to objects after dry-run which counts objects to be changed
+func applyTags(service *s3.S3, bucket string, keys []string, change TagChange, selector *Selector, status *Status) error {
+	updates := make([]Tags, len(keys))
+	failed, err := parallel(len(keys), func(i int) error {
+		tags, err := fetchTags(service, bucket, keys[i], "")
+		if err != nil {
+			return err
+		}
+		if tags, changed := tags.Apply(change); changed {
+			updates[i] = tags
+		}
+		return nil
+	}, func(done int) {
+		status.Message(fmt.Sprintf("Inspecting tags %d / %d ...", done, len(keys)), 0)
+	})
+	if err != nil {
+		<-status.Failure(fmt.Sprintf("Failed to get tags of %s", keys[failed]), err, 2)
+		return nil
+	}
+	targets := []int{}
+	for i, tags := range updates {
+		if tags != nil {
+			targets = append(targets, i)
+		}
+	}
+	if len(targets) == 0 {
+		<-status.Warn("Nothing to change", 1)
+		return nil
+	}
+
+	message := fmt.Sprintf("Dry run: tag changes will be applied to %d of %d objects. Apply? (Enter: apply, Esc: cancel)", len(targets), len(keys))
+	if !selector.Preview(change.lines(), message) {
+		return nil
+	}
+
+	failed, err = parallel(len(targets), func(i int) error {
+		t := targets[i]
+		return putTags(service, bucket, keys[t], updates[t])
+	}, func(done int) {
+		status.Info(fmt.Sprintf("Updating tags %d / %d ...", done, len(targets)), 0)
+	})
+	if err != nil {
+		<-status.Failure(fmt.Sprintf("Failed to update tags of %s", keys[targets[failed]]), err, 2)
+		return nil
+	}
+	<-status.Info(fmt.Sprintf("Updated tags of %d objects", len(targets)), 1)
+	return nil
+}
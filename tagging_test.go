@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestTagsApply(t *testing.T) {
+	tags := Tags{"env": "prod", "team": "web"}
+	updated, changed := tags.Apply(TagChange{
+		"env":   aws.String("prod"),
+		"team":  nil,
+		"owner": aws.String("alice"),
+	})
+	if !changed {
+		t.Errorf("changed expected true")
+	}
+	if len(updated) != 2 || updated["owner"] != "alice" || updated["env"] != "prod" {
+		t.Errorf("unexpected updated tags %v", updated)
+	}
+
+	if _, changed := tags.Apply(TagChange{"env": aws.String("prod"), "missing": nil}); changed {
+		t.Errorf("changed expected false for same values")
+	}
+}
+
+func TestTagsQuery(t *testing.T) {
+	tags := Tags{"env": "prod", "owner": "a b"}
+	if query := tags.query(); query != "env=prod&owner=a+b" {
+		t.Errorf("unexpected query %s", query)
+	}
+}
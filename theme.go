@@ -0,0 +1,490 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Terminal color mode
+type ColorMode int
+
+const (
+	ColorNone ColorMode = iota
+	Color8
+	Color256
+	ColorTrue
+)
+
+// Text attributes which can be combined with color
+const attrMask = termbox.AttrBold | termbox.AttrBlink | termbox.AttrHidden | termbox.AttrDim |
+	termbox.AttrUnderline | termbox.AttrCursive | termbox.AttrReverse
+
+// Foreground and background pair
+type Style struct {
+	Fg termbox.Attribute
+	Bg termbox.Attribute
+}
+
+// Color theme which has named color slots
+type Theme struct {
+
+	// Header row
+	Header Style
+
+	// Normal text
+	Text Style
+
+	// Titles like bookmark names, rule IDs and overlay title
+	Title Style
+
+	// Matched text of filter
+	Highlight Style
+
+	// Last modified and visited dates
+	Date Style
+
+	// Object sizes
+	Size Style
+
+	// Storage class
+	Storage Style
+
+	// Storage class of archived object
+	Archived Style
+
+	// Directory names
+	Directory Style
+
+	// Parent directory row
+	Parent Style
+
+	// Object hidden by delete marker
+	Deleted Style
+
+	// Positive labels like enabled rule and version label
+	Success Style
+
+	// Negative labels like disabled rule and delete marker
+	Danger Style
+
+	// Changed values and noticeable settings
+	Notice Style
+
+	// Supplementary text and placeholders
+	Muted Style
+
+	// Labels of info and form fields
+	Label Style
+
+	// Line numbers of viewer
+	LineNumber Style
+
+	// Cursor row
+	Cursor Style
+
+	// Marked rows
+	Mark Style
+
+	// Status message
+	Message Style
+
+	// Status info
+	Info Style
+
+	// Status warning
+	Warn Style
+
+	// Status error
+	Error Style
+
+	// Color mode which colors are resolved for
+	mode ColorMode
+}
+
+// Get slots by name for applying specs
+func (t *Theme) slots() map[string]*Style {
+	return map[string]*Style{
+		"header":      &t.Header,
+		"text":        &t.Text,
+		"title":       &t.Title,
+		"highlight":   &t.Highlight,
+		"date":        &t.Date,
+		"size":        &t.Size,
+		"storage":     &t.Storage,
+		"archived":    &t.Archived,
+		"directory":   &t.Directory,
+		"parent":      &t.Parent,
+		"deleted":     &t.Deleted,
+		"success":     &t.Success,
+		"danger":      &t.Danger,
+		"notice":      &t.Notice,
+		"muted":       &t.Muted,
+		"label":       &t.Label,
+		"line_number": &t.LineNumber,
+		"cursor":      &t.Cursor,
+		"mark":        &t.Mark,
+		"message":     &t.Message,
+		"info":        &t.Info,
+		"warn":        &t.Warn,
+		"error":       &t.Error,
+	}
+}
+
+// Built-in themes. Style spec is "[attributes] [foreground] [on background]"
+var builtinThemes = map[string]map[string]string{
+	"dark": {
+		"header":      "bold green",
+		"text":        "white",
+		"title":       "bold white",
+		"highlight":   "yellow",
+		"date":        "white",
+		"size":        "cyan",
+		"storage":     "blue",
+		"archived":    "magenta",
+		"directory":   "bold green",
+		"parent":      "white on blue",
+		"deleted":     "red",
+		"success":     "green",
+		"danger":      "red",
+		"notice":      "yellow",
+		"muted":       "blue",
+		"label":       "cyan",
+		"line_number": "blue",
+		"cursor":      "on magenta",
+		"mark":        "on blue",
+		"message":     "white",
+		"info":        "black on cyan",
+		"warn":        "black on yellow",
+		"error":       "white on red",
+	},
+	"light": {
+		"header":      "bold blue",
+		"text":        "black",
+		"title":       "bold black",
+		"highlight":   "bold magenta",
+		"date":        "black",
+		"size":        "blue",
+		"storage":     "cyan",
+		"archived":    "magenta",
+		"directory":   "bold blue",
+		"parent":      "white on blue",
+		"deleted":     "red",
+		"success":     "green",
+		"danger":      "red",
+		"notice":      "magenta",
+		"muted":       "cyan",
+		"label":       "blue",
+		"line_number": "cyan",
+		"cursor":      "on cyan",
+		"mark":        "on yellow",
+		"message":     "black",
+		"info":        "white on blue",
+		"warn":        "black on yellow",
+		"error":       "white on red",
+	},
+	"high-contrast": {
+		"header":      "bold bright-yellow",
+		"text":        "bright-white",
+		"title":       "bold bright-white",
+		"highlight":   "black on bright-yellow",
+		"date":        "bright-white",
+		"size":        "bright-cyan",
+		"storage":     "bright-cyan",
+		"archived":    "bright-magenta",
+		"directory":   "bold bright-green",
+		"parent":      "black on bright-white",
+		"deleted":     "bold bright-red",
+		"success":     "bright-green",
+		"danger":      "bright-red",
+		"notice":      "bright-yellow",
+		"muted":       "bright-cyan",
+		"label":       "bright-cyan",
+		"line_number": "bright-white",
+		"cursor":      "reverse",
+		"mark":        "underline",
+		"message":     "bright-white",
+		"info":        "black on bright-cyan",
+		"warn":        "black on bright-yellow",
+		"error":       "bold bright-white on red",
+	},
+}
+
+// Theme for NO_COLOR, only attributes are used
+var monochromeTheme = map[string]string{
+	"header":    "bold",
+	"title":     "bold",
+	"highlight": "underline",
+	"directory": "bold",
+	"parent":    "reverse",
+	"deleted":   "dim",
+	"muted":     "dim",
+	"cursor":    "reverse",
+	"mark":      "underline",
+	"info":      "bold",
+	"warn":      "reverse",
+	"error":     "bold reverse",
+}
+
+// ANSI color names, index is xterm color number
+var ansiColors = []string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"bright-black", "bright-red", "bright-green", "bright-yellow", "bright-blue", "bright-magenta", "bright-cyan", "bright-white",
+}
+
+// xterm RGB values of ANSI colors
+var ansiRGB = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// Active theme
+var theme, _ = NewTheme("dark", nil, Color8)
+
+// Detect color mode from setting and environment
+func detectColorMode(setting string) (ColorMode, error) {
+	switch setting {
+	case "none":
+		return ColorNone, nil
+	case "8":
+		return Color8, nil
+	case "256":
+		return Color256, nil
+	case "truecolor":
+		return ColorTrue, nil
+	case "", "auto":
+		break
+	default:
+		return ColorNone, fmt.Errorf("unknown color mode %s, available: auto, none, 8, 256, truecolor", setting)
+	}
+
+	// https://no-color.org/
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return ColorNone, nil
+	}
+	if colorTerm := os.Getenv("COLORTERM"); colorTerm == "truecolor" || colorTerm == "24bit" {
+		return ColorTrue, nil
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return Color256, nil
+	}
+	return Color8, nil
+}
+
+// Create theme from built-in or custom themes. Custom theme overrides slots of "base" theme (default "dark")
+func NewTheme(name string, custom map[string]map[string]string, mode ColorMode) (*Theme, error) {
+	if name == "" {
+		name = "dark"
+	}
+	specs := map[string]string{}
+	if overrides, ok := custom[name]; ok {
+		base := overrides["base"]
+		if base == "" {
+			base = "dark"
+		}
+		baseSpecs, ok := builtinThemes[base]
+		if !ok {
+			return nil, fmt.Errorf("unknown base theme %s", base)
+		}
+		for slot, spec := range baseSpecs {
+			specs[slot] = spec
+		}
+		for slot, spec := range overrides {
+			if slot != "base" {
+				specs[slot] = spec
+			}
+		}
+	} else if builtin, ok := builtinThemes[name]; ok {
+		specs = builtin
+	} else {
+		return nil, fmt.Errorf("unknown theme %s, available: %s", name, strings.Join(themeNames(custom), ", "))
+	}
+	if mode == ColorNone {
+		specs = monochromeTheme
+	}
+
+	t := &Theme{mode: mode}
+	slots := t.slots()
+	for slot, spec := range specs {
+		style, ok := slots[slot]
+		if !ok {
+			return nil, fmt.Errorf("unknown color slot %s", slot)
+		}
+		parsed, err := parseStyle(spec, mode)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", slot, err)
+		}
+		*style = parsed
+	}
+	return t, nil
+}
+
+// Get built-in and custom theme names
+func themeNames(custom map[string]map[string]string) []string {
+	names := []string{}
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	for name := range custom {
+		if _, ok := builtinThemes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get termbox output mode for color mode
+func (t *Theme) outputMode() termbox.OutputMode {
+	switch t.mode {
+	case Color256:
+		return termbox.Output256
+	case ColorTrue:
+		return termbox.OutputRGB
+	}
+	return termbox.OutputNormal
+}
+
+// Parse style spec like "bold green", "black on cyan", "#ff8700 on 236"
+func parseStyle(spec string, mode ColorMode) (Style, error) {
+	style := Style{}
+	background := false
+	var attrs termbox.Attribute
+	for _, token := range strings.Fields(strings.ToLower(spec)) {
+		switch token {
+		case "on":
+			background = true
+			continue
+		case "bold":
+			attrs |= termbox.AttrBold
+			continue
+		case "dim":
+			attrs |= termbox.AttrDim
+			continue
+		case "underline":
+			attrs |= termbox.AttrUnderline
+			continue
+		case "reverse":
+			attrs |= termbox.AttrReverse
+			continue
+		}
+		color, err := parseColor(token, mode)
+		if err != nil {
+			return style, err
+		}
+		if background {
+			style.Bg = color
+		} else {
+			style.Fg = color
+		}
+	}
+	// termbox treats attributes without color as black in RGB mode, so use explicit white
+	if mode == ColorTrue && style.Fg == termbox.ColorDefault && attrs != 0 {
+		style.Fg = termbox.RGBToAttribute(ansiRGB[7][0], ansiRGB[7][1], ansiRGB[7][2])
+	}
+	style.Fg |= attrs
+	return style, nil
+}
+
+// Parse color name, xterm color number (0-255) or "#rrggbb" and resolve for color mode
+func parseColor(token string, mode ColorMode) (termbox.Attribute, error) {
+	if token == "default" {
+		return termbox.ColorDefault, nil
+	}
+	for i, name := range ansiColors {
+		if token == name {
+			return resolveIndex(i, mode), nil
+		}
+	}
+	if strings.HasPrefix(token, "#") && len(token) == 7 {
+		v, err := strconv.ParseUint(token[1:], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid color %s", token)
+		}
+		return resolveRGB(uint8(v>>16), uint8(v>>8), uint8(v), mode), nil
+	}
+	if n, err := strconv.Atoi(token); err == nil && n >= 0 && n <= 255 {
+		return resolveIndex(n, mode), nil
+	}
+	return 0, fmt.Errorf("invalid color %s", token)
+}
+
+// Resolve xterm color number for color mode
+func resolveIndex(n int, mode ColorMode) termbox.Attribute {
+	switch mode {
+	case ColorNone:
+		return termbox.ColorDefault
+	case Color256:
+		return termbox.Attribute(n + 1)
+	}
+	r, g, b := xtermRGB(n)
+	if mode == ColorTrue {
+		return termbox.RGBToAttribute(r, g, b)
+	}
+	if n < 16 {
+		return termbox.Attribute(n%8) + termbox.ColorBlack
+	}
+	return resolveRGB(r, g, b, mode)
+}
+
+// Resolve RGB color for color mode, nearest color is used for 8 and 256 colors
+func resolveRGB(r, g, b uint8, mode ColorMode) termbox.Attribute {
+	switch mode {
+	case ColorNone:
+		return termbox.ColorDefault
+	case ColorTrue:
+		return termbox.RGBToAttribute(r, g, b)
+	}
+	candidates := 8
+	if mode == Color256 {
+		candidates = 256
+	}
+	nearest, distance := 0, -1
+	for n := 0; n < candidates; n++ {
+		cr, cg, cb := xtermRGB(n)
+		d := sq(int(r)-int(cr)) + sq(int(g)-int(cg)) + sq(int(b)-int(cb))
+		if distance < 0 || d < distance {
+			nearest, distance = n, d
+		}
+	}
+	return termbox.Attribute(nearest + 1)
+}
+
+// Get RGB of xterm color number
+func xtermRGB(n int) (uint8, uint8, uint8) {
+	switch {
+	case n < 16:
+		return ansiRGB[n][0], ansiRGB[n][1], ansiRGB[n][2]
+	case n < 232:
+		// 6x6x6 color cube
+		levels := []uint8{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return levels[n/36], levels[(n/6)%6], levels[n%6]
+	default:
+		gray := uint8(8 + (n-232)*10)
+		return gray, gray, gray
+	}
+}
+
+// Square of int
+func sq(v int) int {
+	return v * v
+}
+
+// Apply style to row cells, keeping characters and foreground colors
+func styleRow(y, width int, style Style) {
+	cb := termbox.CellBuffer()
+	offset := y * width
+	for i := 0; i < width && offset+i < len(cb); i++ {
+		cell := cb[offset+i]
+		if style.Bg != termbox.ColorDefault {
+			cell.Bg = style.Bg
+		}
+		cell.Fg |= style.Fg & attrMask
+		cb[offset+i] = cell
+	}
+}
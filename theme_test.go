@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestParseStyle(t *testing.T) {
+	tests := map[string]Style{
+		"":                 {},
+		"white":            {Fg: termbox.ColorWhite},
+		"bold green":       {Fg: termbox.ColorGreen | termbox.AttrBold},
+		"black on cyan":    {Fg: termbox.ColorBlack, Bg: termbox.ColorCyan},
+		"on magenta":       {Bg: termbox.ColorMagenta},
+		"reverse":          {Fg: termbox.AttrReverse},
+		"bright-red":       {Fg: termbox.ColorRed},
+		"#ff0000 on 4":     {Fg: termbox.ColorRed, Bg: termbox.ColorBlue},
+		"default on white": {Bg: termbox.ColorWhite},
+	}
+	for spec, expected := range tests {
+		actual, err := parseStyle(spec, Color8)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", spec, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("%s: expected %v, actual %v", spec, expected, actual)
+		}
+	}
+	for _, spec := range []string{"purple", "#12345", "256"} {
+		if _, err := parseStyle(spec, Color8); err == nil {
+			t.Errorf("%s: expected error", spec)
+		}
+	}
+}
+
+func TestParseColorModes(t *testing.T) {
+	tests := []struct {
+		token    string
+		mode     ColorMode
+		expected termbox.Attribute
+	}{
+		{"red", Color256, termbox.Attribute(2)},
+		{"bright-red", Color256, termbox.Attribute(10)},
+		{"214", Color256, termbox.Attribute(215)},
+		{"#ff8700", Color256, termbox.Attribute(209)},
+		{"#ff8700", ColorTrue, termbox.RGBToAttribute(0xff, 0x87, 0x00)},
+		{"red", ColorTrue, termbox.RGBToAttribute(205, 0, 0)},
+		{"214", Color8, termbox.ColorYellow},
+		{"red", ColorNone, termbox.ColorDefault},
+	}
+	for _, tt := range tests {
+		actual, err := parseColor(tt.token, tt.mode)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.token, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("%s in mode %d: expected %d, actual %d", tt.token, tt.mode, tt.expected, actual)
+		}
+	}
+}
+
+func TestNewTheme(t *testing.T) {
+	for _, name := range themeNames(nil) {
+		if _, err := NewTheme(name, nil, Color256); err != nil {
+			t.Errorf("%s: unexpected error %s", name, err)
+		}
+	}
+
+	custom := map[string]map[string]string{
+		"mine": {"base": "light", "cursor": "on red"},
+	}
+	th, err := NewTheme("mine", custom, Color8)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if th.Cursor.Bg != termbox.ColorRed {
+		t.Errorf("expected overridden cursor, actual %v", th.Cursor)
+	}
+	if th.Text.Fg != termbox.ColorBlack {
+		t.Errorf("expected text of light theme, actual %v", th.Text)
+	}
+
+	if _, err := NewTheme("unknown", custom, Color8); err == nil {
+		t.Errorf("expected error for unknown theme")
+	}
+	if _, err := NewTheme("bad", map[string]map[string]string{"bad": {"unknown": "red"}}, Color8); err == nil {
+		t.Errorf("expected error for unknown slot")
+	}
+	if _, err := NewTheme("bad", map[string]map[string]string{"bad": {"base": "missing"}}, Color8); err == nil {
+		t.Errorf("expected error for unknown base")
+	}
+
+	mono, err := NewTheme("dark", nil, ColorNone)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if mono.Cursor != (Style{Fg: termbox.AttrReverse}) || mono.Text != (Style{}) {
+		t.Errorf("expected attributes only, actual cursor %v, text %v", mono.Cursor, mono.Text)
+	}
+}
+
+func TestDetectColorMode(t *testing.T) {
+	for _, key := range []string{"NO_COLOR", "COLORTERM", "TERM"} {
+		defer os.Setenv(key, os.Getenv(key))
+	}
+	os.Setenv("NO_COLOR", "")
+
+	os.Setenv("COLORTERM", "truecolor")
+	os.Setenv("TERM", "xterm-256color")
+	if mode, _ := detectColorMode("auto"); mode != ColorTrue {
+		t.Errorf("expected truecolor, actual %d", mode)
+	}
+	os.Setenv("COLORTERM", "")
+	if mode, _ := detectColorMode(""); mode != Color256 {
+		t.Errorf("expected 256 colors, actual %d", mode)
+	}
+	os.Setenv("TERM", "xterm")
+	if mode, _ := detectColorMode(""); mode != Color8 {
+		t.Errorf("expected 8 colors, actual %d", mode)
+	}
+	os.Setenv("NO_COLOR", "1")
+	if mode, _ := detectColorMode(""); mode != ColorNone {
+		t.Errorf("expected no color with NO_COLOR, actual %d", mode)
+	}
+	if mode, _ := detectColorMode("256"); mode != Color256 {
+		t.Errorf("expected explicit mode overrides NO_COLOR, actual %d", mode)
+	}
+	if _, err := detectColorMode("16"); err == nil {
+		t.Errorf("expected error for unknown mode")
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// S3 object version struct
+type Version struct {
+
+	// File size
+	size int64
+
+	// File name
+	key string
+
+	// Version ID
+	versionId string
+
+	// Last modified time
+	lastModified time.Time
+
+	// Storage class
+	storageClass string
+
+	// latest version flag
+	latest bool
+
+	// delete marker flag
+	deleteMarker bool
+
+	Writer
+}
+
+// Create new version pointer from S3 object version
+func NewVersion(key string, v *s3.ObjectVersion) *Version {
+	return &Version{
+		size:         *v.Size,
+		key:          key,
+		versionId:    *v.VersionId,
+		lastModified: *v.LastModified,
+		storageClass: aws.StringValue(v.StorageClass),
+		latest:       *v.IsLatest,
+	}
+}
+
+// Create new version pointer from S3 delete marker
+func NewDeleteMarker(key string, d *s3.DeleteMarkerEntry) *Version {
+	return &Version{
+		key:          key,
+		versionId:    *d.VersionId,
+		lastModified: *d.LastModified,
+		latest:       *d.IsLatest,
+		deleteMarker: true,
+	}
+}
+
+// Get version label
+func (v *Version) label() string {
+	switch {
+	case v.deleteMarker && v.latest:
+		return "(delete marker, latest)"
+	case v.deleteMarker:
+		return "(delete marker)"
+	case v.latest:
+		return "(latest)"
+	default:
+		return ""
+	}
+}
+
+// Writer::String implementation
+func (v *Version) String() string {
+	if v.deleteMarker {
+		return fmt.Sprintf("%s %10s  %s %s %s", localTime(v.lastModified), "-", v.key, v.versionId, v.label())
+	}
+	return fmt.Sprintf("%s %10d  %s %s %s", localTime(v.lastModified), v.size, v.key, v.versionId, v.label())
+}
+
+// Writer::Write implementation
+func (v *Version) Write(y int, filter string) {
+	i := 0
+	for _, r := range []rune(localTime(v.lastModified)) {
+		termbox.SetCell(i, y, r, theme.Date.Fg, theme.Date.Bg)
+		i++
+	}
+	if v.deleteMarker {
+		i = writeColumns(i, y, "-", "")
+	} else {
+		i = writeColumns(i, y, fmt.Sprint(v.size), v.storageClass)
+	}
+
+	keyStyle := theme.Text
+	if v.deleteMarker {
+		keyStyle = theme.Deleted
+	}
+	first, last := findHighlightRange(v.key, filter)
+	for j, r := range []rune(v.key) {
+		style := keyStyle
+		if j >= first && j < last {
+			style = theme.Highlight
+		}
+		termbox.SetCell(i, y, r, style.Fg, style.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+	for _, r := range []rune(fmt.Sprintf("  %s ", v.versionId)) {
+		termbox.SetCell(i, y, r, theme.Muted.Fg, theme.Muted.Bg)
+		i++
+	}
+
+	labelStyle := theme.Success
+	if v.deleteMarker {
+		labelStyle = theme.Danger
+	}
+	for _, r := range []rune(v.label()) {
+		termbox.SetCell(i, y, r, labelStyle.Fg, labelStyle.Bg)
+		i++
+	}
+}
+
+// Define Version list type
+type Versions []*Version
+
+// Transform to Selectable type
+func (v Versions) Selectable() Selectable {
+	s := Selectable{}
+	for _, w := range v {
+		s = append(s, w)
+	}
+
+	return s
+}
+
+// Filter and format object version list into directories and versions
+func formatVersions(s3Versions []*s3.ObjectVersion, s3Markers []*s3.DeleteMarkerEntry, prefix []string) (Objects, Versions) {
+	replace := ""
+	if len(prefix) > 0 {
+		replace = strings.Join(prefix, "/") + "/"
+	}
+	objects := Objects{}
+	versions := Versions{}
+	unique := map[string]struct{}{}
+
+	// If key contains "/", we deal with it as directory
+	directory := func(key string, lastModified time.Time) bool {
+		if !strings.Contains(key, "/") {
+			return false
+		}
+		parts := strings.Split(key, "/")
+		if _, exist := unique[parts[0]]; !exist {
+			unique[parts[0]] = struct{}{}
+			objects = append(objects, NewObject(parts[0], 0, lastModified, "", true))
+		}
+		return true
+	}
+
+	for _, v := range s3Versions {
+		key := strings.Replace(*v.Key, replace, "", 1)
+		if !directory(key, *v.LastModified) {
+			versions = append(versions, NewVersion(key, v))
+		}
+	}
+	for _, d := range s3Markers {
+		key := strings.Replace(*d.Key, replace, "", 1)
+		if !directory(key, *d.LastModified) {
+			versions = append(versions, NewDeleteMarker(key, d))
+		}
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].key < objects[j].key
+	})
+	sortVersions(versions)
+
+	return objects, versions
+}
+
+// Sort by key, and newer version comes first
+func sortVersions(versions Versions) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].key != versions[j].key {
+			return versions[i].key < versions[j].key
+		}
+		return versions[i].lastModified.After(versions[j].lastModified)
+	})
+}
+
+// Fetch all versions and delete markers which start with prefix
+func fetchVersions(service *s3.S3, bucket, prefix string) ([]*s3.ObjectVersion, []*s3.DeleteMarkerEntry, error) {
+	var versions []*s3.ObjectVersion
+	var markers []*s3.DeleteMarkerEntry
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+	}
+	if prefix != "" {
+		input = input.SetPrefix(prefix)
+	}
+	err := service.ListObjectVersionsPages(input, func(result *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		versions = append(versions, result.Versions...)
+		markers = append(markers, result.DeleteMarkers...)
+		return true
+	})
+	return versions, markers, err
+}
+
+// Collect delete markers which hide objects. Stacked delete markers are collected until real version appears
+func undeleteTargets(s3Versions []*s3.ObjectVersion, s3Markers []*s3.DeleteMarkerEntry) []*s3.ObjectIdentifier {
+	history := map[string]Versions{}
+	keys := []string{}
+	add := func(key string, v *Version) {
+		if _, exist := history[key]; !exist {
+			keys = append(keys, key)
+		}
+		history[key] = append(history[key], v)
+	}
+	for _, v := range s3Versions {
+		add(*v.Key, NewVersion(*v.Key, v))
+	}
+	for _, d := range s3Markers {
+		add(*d.Key, NewDeleteMarker(*d.Key, d))
+	}
+	sort.Strings(keys)
+
+	targets := []*s3.ObjectIdentifier{}
+	for _, key := range keys {
+		versions := history[key]
+		sortVersions(versions)
+		for _, v := range versions {
+			if !v.deleteMarker {
+				break
+			}
+			targets = append(targets, &s3.ObjectIdentifier{
+				Key:       aws.String(key),
+				VersionId: aws.String(v.versionId),
+			})
+		}
+	}
+	return targets
+}
+
+// Remove delete markers of key, or all keys under prefix, and returns undeleted amount
+func undelete(service *s3.S3, bucket, prefix string, exact bool) (int, error) {
+	s3Versions, s3Markers, err := fetchVersions(service, bucket, prefix)
+	if err != nil {
+		return 0, err
+	}
+	if exact {
+		versions := []*s3.ObjectVersion{}
+		for _, v := range s3Versions {
+			if *v.Key == prefix {
+				versions = append(versions, v)
+			}
+		}
+		markers := []*s3.DeleteMarkerEntry{}
+		for _, d := range s3Markers {
+			if *d.Key == prefix {
+				markers = append(markers, d)
+			}
+		}
+		s3Versions, s3Markers = versions, markers
+	}
+
+	targets := undeleteTargets(s3Versions, s3Markers)
+	undeleted := map[string]struct{}{}
+	// DeleteObjects accepts 1000 keys at most
+	for i := 0; i < len(targets); i += 1000 {
+		end := i + 1000
+		if end > len(targets) {
+			end = len(targets)
+		}
+		result, err := service.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &s3.Delete{
+				Objects: targets[i:end],
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return len(undeleted), err
+		}
+		if len(result.Errors) > 0 {
+			return len(undeleted), fmt.Errorf("Failed to undelete %s: %s", *result.Errors[0].Key, *result.Errors[0].Message)
+		}
+		for _, t := range targets[i:end] {
+			undeleted[*t.Key] = struct{}{}
+		}
+	}
+	return len(undeleted), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestFormatVersions(t *testing.T) {
+	now := time.Now()
+	s3Versions := []*s3.ObjectVersion{
+		{Key: aws.String("foo/a.txt"), VersionId: aws.String("v1"), IsLatest: aws.Bool(false), Size: aws.Int64(1), LastModified: aws.Time(now.Add(-time.Hour))},
+		{Key: aws.String("foo/a.txt"), VersionId: aws.String("v2"), IsLatest: aws.Bool(true), Size: aws.Int64(2), LastModified: aws.Time(now)},
+		{Key: aws.String("foo/bar/b.txt"), VersionId: aws.String("v3"), IsLatest: aws.Bool(true), Size: aws.Int64(3), LastModified: aws.Time(now)},
+	}
+	s3Markers := []*s3.DeleteMarkerEntry{
+		{Key: aws.String("foo/c.txt"), VersionId: aws.String("m1"), IsLatest: aws.Bool(true), LastModified: aws.Time(now)},
+	}
+	objects, versions := formatVersions(s3Versions, s3Markers, []string{"foo"})
+	if len(objects) != 1 || objects[0].key != "bar" || !objects[0].dir {
+		t.Errorf("expected bar directory, actual %v", objects)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("versions length expected 3, actual %d", len(versions))
+	}
+	if versions[0].versionId != "v2" || versions[1].versionId != "v1" {
+		t.Errorf("expected newer version first, actual %s, %s", versions[0].versionId, versions[1].versionId)
+	}
+	if !versions[2].deleteMarker || versions[2].key != "c.txt" {
+		t.Errorf("expected delete marker c.txt, actual %s", versions[2].key)
+	}
+}
+
+func TestUndeleteTargets(t *testing.T) {
+	now := time.Now()
+	s3Versions := []*s3.ObjectVersion{
+		{Key: aws.String("a.txt"), VersionId: aws.String("v1"), IsLatest: aws.Bool(false), Size: aws.Int64(1), LastModified: aws.Time(now.Add(-2 * time.Hour))},
+		{Key: aws.String("b.txt"), VersionId: aws.String("v2"), IsLatest: aws.Bool(true), Size: aws.Int64(1), LastModified: aws.Time(now)},
+	}
+	s3Markers := []*s3.DeleteMarkerEntry{
+		{Key: aws.String("a.txt"), VersionId: aws.String("m1"), IsLatest: aws.Bool(false), LastModified: aws.Time(now.Add(-time.Hour))},
+		{Key: aws.String("a.txt"), VersionId: aws.String("m2"), IsLatest: aws.Bool(true), LastModified: aws.Time(now)},
+		{Key: aws.String("b.txt"), VersionId: aws.String("m3"), IsLatest: aws.Bool(false), LastModified: aws.Time(now.Add(-time.Hour))},
+	}
+	targets := undeleteTargets(s3Versions, s3Markers)
+	if len(targets) != 2 {
+		t.Fatalf("targets length expected 2, actual %d", len(targets))
+	}
+	if *targets[0].VersionId != "m2" || *targets[1].VersionId != "m1" {
+		t.Errorf("expected stacked markers m2, m1, actual %s, %s", *targets[0].VersionId, *targets[1].VersionId)
+	}
+}
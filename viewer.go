@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// Max byte size which viewer can display
+const maxViewSize = 10 * 1024 * 1024
+
+// Text line struct for viewer
+type TextLine struct {
+
+	// Line number
+	number int
+
+	// Line text
+	text string
+
+	Writer
+}
+
+// Writer::String implementation
+func (t *TextLine) String() string {
+	return t.text
+}
+
+// Writer::Write implementation
+func (t *TextLine) Write(y int, filter string) {
+	i := 0
+	for _, r := range []rune(fmt.Sprintf("%6d  ", t.number)) {
+		termbox.SetCell(i, y, r, theme.LineNumber.Fg, theme.LineNumber.Bg)
+		i++
+	}
+
+	first, last := findHighlightRange(t.text, filter)
+	for j, r := range []rune(t.text) {
+		style := theme.Text
+		if j >= first && j < last {
+			style = theme.Highlight
+		}
+		termbox.SetCell(i, y, r, style.Fg, style.Bg)
+		i += runewidth.RuneWidth(r)
+	}
+}
+
+// Text viewer struct
+type Viewer struct {
+
+	// Status Writer
+	status *Status
+
+	// Injected Selector
+	selector *Selector
+
+	// row offset for termbox
+	offset int
+}
+
+// Create Viewer pointer
+func NewViewer(selector *Selector, status *Status, offset int) *Viewer {
+	return &Viewer{
+		selector: selector,
+		status:   status,
+		offset:   offset,
+	}
+}
+
+// View content as text lines. Filter query works as grep
+func (v *Viewer) View(name string, content []byte) error {
+	if len(content) > maxViewSize {
+		return fmt.Errorf("%s is too large to view", name)
+	}
+	if isBinary(content) {
+		return fmt.Errorf("%s seems to be binary file", name)
+	}
+
+	lines := Selectable{}
+	for i, line := range strings.Split(string(content), "\n") {
+		lines = append(lines, &TextLine{
+			number: i + 1,
+			text:   strings.Replace(strings.TrimRight(line, "\r"), "\t", "    ", -1),
+		})
+	}
+
+	v.selector.SetOffset(v.offset)
+	v.status.Message(fmt.Sprintf("Viewing %s", name), 0)
+	// Either Enter or Esc finishes viewing
+	v.selector.Choose(lines)
+	v.status.Clear()
+	return nil
+}
+
+// Check content seems to be binary
+func isBinary(content []byte) bool {
+	head := content
+	if len(head) > 512 {
+		head = head[0:512]
+	}
+	return bytes.IndexByte(head, 0) != -1
+}